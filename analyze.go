@@ -0,0 +1,192 @@
+package bbcdisasm
+
+import "sort"
+
+// Region classifies how a byte of the program was determined to be used by
+// Analyze.
+type Region int
+
+// Regions
+const (
+	// RegionUnknown is a byte that Analyze's control-flow trace never
+	// reached. It may be code reachable only through an unresolved indirect
+	// jump, or it may be data.
+	RegionUnknown Region = iota
+	// RegionCode is a byte that Analyze's control-flow trace identified as
+	// belonging to an instruction.
+	RegionCode
+)
+
+// UnresolvedIndirectJMP returns the addresses, after Analyze, of `JMP (&1234)`
+// and `JMP (&1234,X)` instructions whose target could not be determined
+// statically. Supply a
+// resolution for one of these addresses via IndirectTargets and re-run
+// Analyze to trace through it.
+func (d *Disassembler) UnresolvedIndirectJMP() []uint {
+	return d.unresolvedIndirectJMP
+}
+
+// Analyze performs a recursive-descent trace of the program's control flow,
+// starting from entries (plus any addresses already present in d.CodeAddrs),
+// following fall-through and branch/jump targets, and stopping at RTS, RTI,
+// BRK and unconditional JMP. It returns a map from address (in load-address
+// space, i.e. with d.BranchAdjust already applied) to the Region it belongs
+// to.
+//
+// If the program covers the OS vector workspace at &200-&235 then the
+// current contents of each vector are also added as entry points, since code
+// is frequently reached only by an indirection through a vector.
+//
+// JMP (&1234) and JMP (&1234,X) indirect targets cannot be resolved by a
+// static trace. Their addresses are recorded and can be retrieved with UnresolvedIndirectJMP;
+// populate d.IndirectTargets with a resolution (e.g. from a supplemental
+// config file) and call Analyze again to trace through them.
+func (d *Disassembler) Analyze(entries []uint) map[uint]Region {
+	regions := make(map[uint]Region)
+	d.unresolvedIndirectJMP = nil
+
+	queue := append([]uint{}, entries...)
+	queue = append(queue, d.CodeAddrs...)
+	queue = append(queue, d.vectorEntries()...)
+	queue = append(queue, d.hardwareVectorEntries()...)
+
+	lo, hi := d.Offset, d.Offset+d.MaxBytes
+	visited := make(map[uint]bool)
+
+	for len(queue) > 0 {
+		pc := queue[0]
+		queue = queue[1:]
+
+		// Entries are supplied in load-address space; convert to an offset
+		// into Program.
+		cursor := pc - d.BranchAdjust
+
+		if cursor < lo || cursor >= hi || visited[cursor] {
+			continue
+		}
+
+		op, opOk := d.opCodes[d.Program[cursor]]
+		if !opOk || cursor+op.Length > hi {
+			// Can't decode a full instruction here; don't claim the byte as
+			// code and don't trace further down this path.
+			continue
+		}
+
+		for i := uint(0); i < op.Length; i++ {
+			visited[cursor+i] = true
+			regions[cursor+i+d.BranchAdjust] = RegionCode
+		}
+
+		instruction := d.Program[cursor : cursor+op.Length]
+
+		switch op.branchOrJump() {
+		case btBranch:
+			queue = append(queue, cursor+op.Length+d.BranchAdjust)
+			queue = append(queue, branchTarget(instruction, cursor, d.BranchAdjust, op.Length))
+		case btJump:
+			if instruction[0] == OpJMPIndirect || instruction[0] == OpJMPIndirectAbsoluteX {
+				addr := cursor + d.BranchAdjust
+				if tgt, ok := d.IndirectTargets[addr]; ok {
+					queue = append(queue, tgt)
+				} else {
+					d.unresolvedIndirectJMP = append(d.unresolvedIndirectJMP, addr)
+				}
+				continue
+			}
+
+			tgt := (uint(instruction[2]) << 8) + uint(instruction[1])
+			queue = append(queue, tgt)
+			if op.Name == "JSR" {
+				// The call returns, so tracing continues after it.
+				queue = append(queue, cursor+op.Length+d.BranchAdjust)
+			}
+		case btNeither:
+			if op.Name == "RTS" || op.Name == "RTI" || op.Name == "BRK" {
+				continue
+			}
+			queue = append(queue, cursor+op.Length+d.BranchAdjust)
+		}
+	}
+
+	return regions
+}
+
+// hardwareVectorAddresses are the fixed 6502 NMI/RESET/IRQ vectors at the top
+// of the address space. Unlike the OS vectors in SymVector, these are wired
+// into the CPU itself, not supplied by the operating system, so they're not
+// part of SymbolTable.
+var hardwareVectorAddresses = []uint{0xFFFA, 0xFFFC, 0xFFFE}
+
+// hardwareVectorEntries returns the current contents of the NMI/RESET/IRQ
+// vectors as candidate entry points, if the program is a full ROM image
+// covering &FFFA-&FFFF.
+func (d *Disassembler) hardwareVectorEntries() []uint {
+	var entries []uint
+	for _, addr := range hardwareVectorAddresses {
+		cursor := addr - d.BranchAdjust
+		if d.BranchAdjust > addr || cursor+1 >= d.Offset+d.MaxBytes || cursor < d.Offset {
+			continue
+		}
+		entries = append(entries, (uint(d.Program[cursor+1])<<8)+uint(d.Program[cursor]))
+	}
+	return entries
+}
+
+// Trace runs Analyze from entries (plus the usual reset/IRQ/NMI and OS
+// vector entry points) and uses the resulting code regions to drive
+// Disassemble: any byte the control-flow pass didn't reach is emitted via
+// printData/printString regardless of whether its value happens to look
+// like a valid opcode, so embedded data no longer needs to be hand-annotated
+// via CodeAddrs to avoid being misinterpreted as code. It also folds the
+// code regions' start addresses into d.CodeAddrs as forced instruction-start
+// boundaries, preserving any existing entries, and returns the result in
+// load-address space.
+func (d *Disassembler) Trace(entries []uint) []uint {
+	regions := d.Analyze(entries)
+	d.tracedRegions = regions
+
+	addrs := append([]uint{}, d.CodeAddrs...)
+	lo, hi := d.Offset+d.BranchAdjust, d.Offset+d.MaxBytes+d.BranchAdjust
+	// Start at lo+1: a boundary at lo itself would be redundant (decoding
+	// always starts at d.Offset regardless of CodeAddrs) and walk's
+	// codeAddrIdx bookkeeping assumes every CodeAddrs entry lies strictly
+	// after the byte decoding starts from.
+	for addr := lo + 1; addr < hi; addr++ {
+		if regions[addr] == RegionCode && regions[addr-1] != RegionCode {
+			addrs = append(addrs, addr)
+		}
+	}
+
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i] < addrs[j] })
+	d.CodeAddrs = addrs
+	return addrs
+}
+
+// vectorEntries returns the current contents of the OS vectors at &200-&235,
+// if the program covers that range, as candidate entry points.
+func (d *Disassembler) vectorEntries() []uint {
+	var entries []uint
+	for addr := range d.Symbols.All(SymVector) {
+		cursor := addr - d.BranchAdjust
+		if d.BranchAdjust > addr || cursor+1 >= d.Offset+d.MaxBytes || cursor < d.Offset {
+			continue
+		}
+		entries = append(entries, (uint(d.Program[cursor+1])<<8)+uint(d.Program[cursor]))
+	}
+	return entries
+}
+
+// branchTarget computes the absolute target address of a branch instruction,
+// accounting for the 6502's relative-to-next-instruction addressing.
+func branchTarget(instruction []byte, cursor, branchAdjust, length uint) uint {
+	boff := int(instruction[1])
+	if length == 3 {
+		boff = int(instruction[2])
+	}
+	if boff > 127 {
+		boff = boff - 256
+	}
+	boff += int(length)
+
+	return cursor + uint(boff) + branchAdjust
+}
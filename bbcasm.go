@@ -0,0 +1,64 @@
+package bbcdisasm
+
+import "fmt"
+
+// Assemble reproduces the original byte stream for a []Instruction as
+// returned by Disassemble, the sibling of the decode pipeline:
+// bytes -> []Instruction -> (text | bytes).
+//
+// Each Instruction's Bytes are used when present, guaranteeing
+// Assemble(Disassemble(program)) == program for any Instruction that came
+// from a real decode. For a hand-built Instruction with no Bytes (e.g.
+// constructed directly from an Opcode, Operand and Label), the bytes are
+// instead encoded from the Opcode and Operand via EncodeInstruction.
+func Assemble(instructions []Instruction) ([]byte, error) {
+	var out []byte
+	for i, ins := range instructions {
+		if len(ins.Bytes) > 0 {
+			out = append(out, ins.Bytes...)
+			continue
+		}
+
+		bs, err := EncodeInstruction(ins)
+		if err != nil {
+			return nil, fmt.Errorf("bbcdisasm: instruction %d at &%04X: %w", i, ins.Addr, err)
+		}
+		out = append(out, bs...)
+	}
+	return out, nil
+}
+
+// EncodeInstruction encodes ins.Opcode and ins.Operand back into bytes,
+// without reference to ins.Bytes. This is the constructive path for an
+// Instruction built directly from an opcode and operand value, rather than
+// decoded from a program.
+func EncodeInstruction(ins Instruction) ([]byte, error) {
+	if ins.IsData() {
+		return nil, fmt.Errorf("cannot encode a data instruction without Bytes")
+	}
+
+	op := ins.Opcode
+	out := []byte{op.Value}
+
+	switch op.AddrMode {
+	case None, Accumulator:
+		switch op.Length {
+		case 2:
+			out = append(out, byte(ins.Operand))
+		case 3:
+			out = append(out, byte(ins.Operand), byte(ins.Operand>>8))
+		}
+	case Immediate, ZeroPage, ZeroPageX, ZeroPageY, IndirectX, IndirectY, IndirectZP:
+		out = append(out, byte(ins.Operand))
+	case Absolute, AbsoluteX, AbsoluteY, Indirect, IndirectAbsoluteX, ZeroPageRel:
+		out = append(out, byte(ins.Operand), byte(ins.Operand>>8))
+	default:
+		return nil, fmt.Errorf("%s: unknown addressing mode", op.Name)
+	}
+
+	if uint(len(out)) != op.Length {
+		return nil, fmt.Errorf("%s: encoded to %d bytes, want %d", op.Name, len(out), op.Length)
+	}
+
+	return out, nil
+}
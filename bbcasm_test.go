@@ -0,0 +1,88 @@
+package bbcdisasm
+
+import (
+	"bytes"
+	"testing"
+	"testing/quick"
+)
+
+// roundTripVariants covers every opcode table EncodeInstruction needs to
+// round-trip through, so an entry whose Length disagrees with what its
+// AddrMode encodes to shows up regardless of which variant introduced it.
+// Value collisions within a table are caught by
+// TestOpCodeTablesHaveNoDuplicateValues instead.
+var roundTripVariants = []CPUVariant{CPUNMOS, CPU65C02, CPUR65C02, CPUWDC65C02}
+
+// stripBytes returns ins with Bytes cleared for every decoded (non-data)
+// instruction, forcing Assemble down the EncodeInstruction path instead of
+// its Bytes fast path. Disassemble always populates Bytes from the original
+// program, so without this the round trip would hold by construction and
+// could never catch an encode bug.
+func stripBytes(instructions []Instruction) []Instruction {
+	out := make([]Instruction, len(instructions))
+	for i, ins := range instructions {
+		if !ins.IsData() {
+			ins.Bytes = nil
+		}
+		out[i] = ins
+	}
+	return out
+}
+
+// TestAssembleRoundTrip is a property-based test: for arbitrary byte
+// sequences, disassembling and then re-encoding via EncodeInstruction (not
+// Assemble's Bytes fast path) must reproduce the original bytes for every
+// documented instruction. A table entry whose Length disagrees with its
+// AddrMode, or an operandOf/EncodeInstruction bug, fails this property. Two
+// entries sharing a Value do not: the map built from a table always yields
+// an Opcode whose Value is the byte looked up, so the surviving entry still
+// round-trips.
+func TestAssembleRoundTrip(t *testing.T) {
+	for _, variant := range roundTripVariants {
+		variant := variant
+		t.Run(variantName(variant), func(t *testing.T) {
+			prop := func(seq [8]byte) bool {
+				// Two bytes of padding give any trailing 3-byte instruction
+				// somewhere to read its operand from without running off the
+				// end of Program; MaxBytes keeps decoding confined to seq.
+				program := append(append([]byte{}, seq[:]...), 0x00, 0x00)
+
+				d := NewDisassemblerForVariant(program, variant)
+				d.MaxBytes = uint(len(seq))
+				var buf bytes.Buffer
+				instructions, _ := d.Disassemble(&buf)
+
+				got, err := Assemble(stripBytes(instructions))
+				if err != nil {
+					t.Errorf("variant %v, seq % X: Assemble: %v", variant, seq, err)
+					return false
+				}
+				// MaxBytes only bounds where an instruction may start, not
+				// where it must end: a multi-byte opcode starting on the
+				// last in-bounds byte reads its operand from the padding,
+				// so got can run past len(seq). Only the in-bounds prefix
+				// is part of the property; compare that.
+				if len(got) > len(seq) {
+					got = got[:len(seq)]
+				}
+				return bytes.Equal(got, seq[:])
+			}
+			if err := quick.Check(prop, &quick.Config{MaxCount: 2000}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func variantName(v CPUVariant) string {
+	switch v {
+	case CPU65C02:
+		return "65C02"
+	case CPUR65C02:
+		return "R65C02"
+	case CPUWDC65C02:
+		return "WDC65C02"
+	default:
+		return "NMOS"
+	}
+}
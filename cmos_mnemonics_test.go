@@ -0,0 +1,72 @@
+package bbcdisasm
+
+import (
+	"bytes"
+	"testing"
+)
+
+// decodeOne disassembles a single instruction's worth of bytes under variant
+// and returns the first non-data Instruction found, for tests that assert on
+// a specific CMOS mnemonic/addressing mode.
+func decodeOne(t *testing.T, variant CPUVariant, program []byte) Instruction {
+	t.Helper()
+	d := NewDisassemblerForVariant(program, variant)
+	d.MaxBytes = uint(len(program))
+	var buf bytes.Buffer
+	instructions, _ := d.Disassemble(&buf)
+	for _, ins := range instructions {
+		if !ins.IsData() {
+			return ins
+		}
+	}
+	t.Fatalf("no instruction decoded from % X under variant %v", program, variant)
+	return Instruction{}
+}
+
+// TestCMOSMnemonics exercises every mnemonic and addressing mode chunk1-1
+// added CMOS decoding support for, so a future change to the 65C02/R65C02
+// tables that breaks one of them fails loudly instead of silently falling
+// back to EQUB data.
+func TestCMOSMnemonics(t *testing.T) {
+	tests := []struct {
+		name     string
+		variant  CPUVariant
+		program  []byte
+		mnemonic string
+		addrMode AddressingMode
+	}{
+		{"BRA rel", CPU65C02, []byte{0x80, 0x02}, "BRA", None},
+		{"STZ zp", CPU65C02, []byte{0x64, 0x12}, "STZ", ZeroPage},
+		{"STZ abs", CPU65C02, []byte{0x9C, 0x34, 0x12}, "STZ", Absolute},
+		{"STZ zp,X", CPU65C02, []byte{0x74, 0x12}, "STZ", ZeroPageX},
+		{"STZ abs,X", CPU65C02, []byte{0x9E, 0x34, 0x12}, "STZ", AbsoluteX},
+		{"INC A", CPU65C02, []byte{0x1A}, "INC", Accumulator},
+		{"DEC A", CPU65C02, []byte{0x3A}, "DEC", Accumulator},
+		{"PHX", CPU65C02, []byte{0xDA}, "PHX", None},
+		{"PLX", CPU65C02, []byte{0xFA}, "PLX", None},
+		{"PHY", CPU65C02, []byte{0x5A}, "PHY", None},
+		{"PLY", CPU65C02, []byte{0x7A}, "PLY", None},
+		{"TRB", CPU65C02, []byte{0x14, 0x12}, "TRB", ZeroPage},
+		{"TSB", CPU65C02, []byte{0x04, 0x12}, "TSB", ZeroPage},
+		{"LDA (zp)", CPU65C02, []byte{0xB2, 0x12}, "LDA", IndirectZP},
+		{"BBR0", CPUR65C02, []byte{0x0F, 0x12, 0x02}, "BBR0", None},
+		{"BBS0", CPUR65C02, []byte{0x8F, 0x12, 0x02}, "BBS0", None},
+		{"RMB0", CPUR65C02, []byte{0x07, 0x12}, "RMB0", ZeroPage},
+		{"SMB0", CPUR65C02, []byte{0x87, 0x12}, "SMB0", ZeroPage},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ins := decodeOne(t, tc.variant, tc.program)
+			if ins.Opcode.Name != tc.mnemonic {
+				t.Errorf("got mnemonic %s, want %s", ins.Opcode.Name, tc.mnemonic)
+			}
+			if ins.Opcode.AddrMode != tc.addrMode {
+				t.Errorf("got addr mode %s, want %s", ins.Opcode.AddrMode, tc.addrMode)
+			}
+			if ins.IsUndocumented() {
+				t.Errorf("%s decoded as undocumented", tc.mnemonic)
+			}
+		})
+	}
+}
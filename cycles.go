@@ -0,0 +1,186 @@
+package bbcdisasm
+
+import "fmt"
+
+// Cycles describes the execution timing of a decoded instruction, in the
+// style of izapple2's cycle-accurate 6502 core.
+type Cycles struct {
+	Base uint8 // cycle count, excluding the adjustments below
+
+	// PageCross is true if the addressing mode may cost 1 extra cycle when
+	// the effective address crosses a page boundary (indexed reads; the
+	// unindexed 65C02 (zp) mode does not set it).
+	PageCross bool
+
+	// DecimalExtra is true for the 65C02 family's ADC/SBC, which cost 1
+	// extra cycle when the CPU is in decimal mode. This can't be known
+	// statically from the bytes alone.
+	DecimalExtra bool
+}
+
+// readWriteModify is the set of read-modify-write mnemonics, which always pay
+// the indexed-addressing page-crossing cycle rather than only conditionally.
+// This includes the illegal opcodes that combine a shift/inc/dec with a
+// second operation (DCP, ISC, RLA, RRA), which are timed as RMW throughout.
+var readWriteModify = map[string]bool{
+	"ASL": true, "LSR": true, "ROL": true, "ROR": true,
+	"INC": true, "DEC": true, "TRB": true, "TSB": true,
+	"DCP": true, "ISC": true, "RLA": true, "RRA": true,
+}
+
+// writeOnly is the set of mnemonics that only ever write the effective
+// address, so indexed addressing always pays the fixed extra cycle rather
+// than only on a page cross. SHA/SHX/SHY/TAS are the unstable illegal
+// opcodes that store a register (combination); LAS is a read and isn't
+// included here.
+var writeOnly = map[string]bool{
+	"STA": true, "STX": true, "STY": true, "STZ": true,
+	"SHA": true, "SHX": true, "SHY": true, "TAS": true,
+}
+
+// CyclesFor returns the cycle timing for op when decoded against variant.
+// Relative branches are annotated by cycleComment rather than from this,
+// since their cycle count depends on the runtime-known branch-taken/page-cross
+// outcome rather than solely on the opcode.
+func CyclesFor(op Opcode, variant CPUVariant) Cycles {
+	rmw := readWriteModify[op.Name]
+	wr := writeOnly[op.Name]
+
+	switch op.AddrMode {
+	case None:
+		return Cycles{Base: noneCycles(op.Name)}
+	case Accumulator:
+		return Cycles{Base: 2}
+	case Immediate:
+		return Cycles{Base: 2}
+	case ZeroPage:
+		if rmw {
+			return Cycles{Base: 5}
+		}
+		return Cycles{Base: 3}
+	case ZeroPageX, ZeroPageY:
+		if rmw {
+			return Cycles{Base: 6}
+		}
+		return Cycles{Base: 4}
+	case ZeroPageRel:
+		return Cycles{Base: 5} // BBRn/BBSn, not taken; +1 if taken
+	case Absolute:
+		if op.Value == OpJMPAbsolute {
+			return Cycles{Base: 3}
+		}
+		if op.Value == OpJSRAbsolute {
+			return Cycles{Base: 6}
+		}
+		if op.Value == 0x5C {
+			return Cycles{Base: 8} // 65C02 NOP &nnnn: an 8-cycle oddball
+		}
+		if rmw {
+			return Cycles{Base: 6}
+		}
+		return Cycles{Base: 4}
+	case Indirect:
+		return Cycles{Base: 5} // JMP (&1234)
+	case IndirectAbsoluteX:
+		return Cycles{Base: 6} // JMP (&1234,X)
+	case AbsoluteX, AbsoluteY:
+		if rmw {
+			// Fixed cost: the extra cycle is always paid, not conditional.
+			return Cycles{Base: 7}
+		}
+		if wr {
+			return Cycles{Base: 5}
+		}
+		decExtra := variant != CPUNMOS && (op.Name == "ADC" || op.Name == "SBC")
+		return Cycles{Base: 4, PageCross: true, DecimalExtra: decExtra}
+	case IndirectX:
+		if rmw {
+			return Cycles{Base: 8}
+		}
+		return Cycles{Base: 6}
+	case IndirectY:
+		if rmw {
+			// Fixed cost: the extra cycle is always paid, not conditional.
+			return Cycles{Base: 8}
+		}
+		if wr {
+			return Cycles{Base: 6}
+		}
+		decExtra := variant != CPUNMOS && (op.Name == "ADC" || op.Name == "SBC")
+		return Cycles{Base: 5, PageCross: true, DecimalExtra: decExtra}
+	case IndirectZP:
+		if wr {
+			return Cycles{Base: 5}
+		}
+		decExtra := variant != CPUNMOS && (op.Name == "ADC" || op.Name == "SBC")
+		return Cycles{Base: 5, DecimalExtra: decExtra}
+	default:
+		return Cycles{Base: 2}
+	}
+}
+
+func noneCycles(name string) uint8 {
+	switch name {
+	case "BRK":
+		return 7
+	case "RTI", "RTS":
+		return 6
+	case "PHA", "PHP", "PHX", "PHY":
+		return 3
+	case "PLA", "PLP", "PLX", "PLY":
+		return 4
+	case "WAI", "STP":
+		return 3
+	case "JAM":
+		return 2 // fetches the opcode, then loops/halts indefinitely
+	case "BBR0", "BBR1", "BBR2", "BBR3", "BBR4", "BBR5", "BBR6", "BBR7",
+		"BBS0", "BBS1", "BBS2", "BBS3", "BBS4", "BBS5", "BBS6", "BBS7":
+		return 5
+	case "RMB0", "RMB1", "RMB2", "RMB3", "RMB4", "RMB5", "RMB6", "RMB7",
+		"SMB0", "SMB1", "SMB2", "SMB3", "SMB4", "SMB5", "SMB6", "SMB7":
+		return 5
+	default:
+		// Implied-addressing register/flag ops: CLC, SEC, TAX, NOP, etc.
+		return 2
+	}
+}
+
+// cycleComment renders the end-of-line cycle annotation for an instruction,
+// e.g. "4c (+1 xpg)" or "2/3c" for a branch.
+func cycleComment(op Opcode, instruction []byte, cursor, branchAdjust uint, variant CPUVariant) string {
+	switch op.branchOrJump() {
+	case btBranch:
+		if op.Length == 3 {
+			// Rockwell/WDC BBRn/BBSn: unlike a regular branch, this is a
+			// flat 5 cycles regardless of whether the bit test branches or
+			// the branch crosses a page.
+			return fmt.Sprintf("%dc", noneCycles(op.Name))
+		}
+		tgt := branchTarget(instruction, cursor, branchAdjust, op.Length)
+		next := cursor + branchAdjust + op.Length
+		crossesPage := (tgt & 0xFF00) != (next & 0xFF00)
+		if crossesPage {
+			return "2/4c"
+		}
+		return "2/3c"
+	}
+
+	c := CyclesFor(op, variant)
+	s := fmt.Sprintf("%dc", c.Base)
+
+	var extra []string
+	if c.PageCross {
+		extra = append(extra, "+1 xpg")
+	}
+	if c.DecimalExtra {
+		extra = append(extra, "+1 dec")
+	}
+	if len(extra) > 0 {
+		s += " (" + extra[0]
+		for _, e := range extra[1:] {
+			s += ", " + e
+		}
+		s += ")"
+	}
+	return s
+}
@@ -43,16 +43,90 @@ type Disassembler struct {
 	// Will be modified by Disassemble().
 	CodeAddrs []uint
 
-	usedOSAddress map[uint]bool
-	usedOSVector  map[uint]bool
-	branchTargets map[uint]int
-	vars          map[string]varDef
+	// Variant selects which member of the 6502 family opcodes are decoded
+	// against, e.g. CPUR65C02 for BBC Master 128 code. The opcode table is
+	// built from it by NewDisassemblerForVariant; assigning the field
+	// afterwards changes only the ShowCycles annotations, not decoding.
+	Variant CPUVariant
+
+	// Illegals selects how much of the NMOS 6502's undocumented opcode space
+	// is recognised as instructions. Disassemble still writes a recognised
+	// undocumented instruction as a data directive of its bytes, with its
+	// mnemonic after "UD" in the trailing comment. Ignored
+	// unless Variant is CPUNMOS. Set via NewDisassemblerForVariantWithIllegals;
+	// defaults to IllegalsNone.
+	Illegals IllegalsMode
+
+	// IndirectTargets supplies known targets for JMP (&1234) indirect
+	// instructions, keyed by the address of the JMP, so that Analyze can
+	// trace through them. Populate from a supplemental config file for
+	// targets that cannot be determined statically.
+	IndirectTargets map[uint]uint
+
+	// ShowCycles enables a --cycles style output mode: each disassembled
+	// instruction gets an end-of-line comment reporting its cycle count,
+	// e.g. "; 4c (+1 xpg)" or "; 2/3c" for a branch.
+	ShowCycles bool
+
+	// Warnings enables AnalyzeRegisters, an optional abstract-interpretation
+	// pass over A/X/Y and the C/Z/N/D/I flags that looks for well-known Beeb
+	// programming mistakes (e.g. ADC with undefined carry). When true,
+	// Disassemble runs it and inlines each Diagnostic as a "WARN:" comment
+	// at the address it concerns, as well as returning the full slice.
+	Warnings bool
+
+	// Symbols resolves OS call, vector and zero page addresses to names.
+	// Defaults to DefaultSymbolTable(MachineBBCB); set to a table loaded via
+	// SymbolTable.Load to disassemble for a different machine.
+	Symbols *SymbolTable
+
+	// Dialect selects the assembler source syntax Disassemble renders
+	// output in. Defaults to BeebasmDialect{}, preserving bbcdisasm's
+	// original output; set to CA65Dialect{}, ACMEDialect{}, DASMDialect{}
+	// or XADialect{} to target a different assembler.
+	Dialect SyntaxDialect
+
+	opCodes               map[byte]Opcode
+	usedOSAddress         map[uint]bool
+	usedOSVector          map[uint]bool
+	branchTargets         map[uint]int
+	vars                  map[string]varDef
+	unresolvedIndirectJMP []uint
+
+	// tracedRegions is set by Trace, and narrows opcode decoding to just the
+	// addresses Trace's control-flow pass actually reached as code; nil
+	// (the default) keeps the legacy behaviour of probing every byte for a
+	// valid opcode.
+	tracedRegions map[uint]Region
 }
 
-// NewDisassembler initializes a new Disassembler with the target progrsm
+// NewDisassembler initializes a new Disassembler with the target program,
+// decoding opcodes for the NMOS 6502 (the Model B and Electron CPU). Use
+// NewDisassemblerForVariant to target a different CPU, such as the Master
+// 128's R65C02.
 func NewDisassembler(program []byte) *Disassembler {
+	return NewDisassemblerForVariant(program, CPUNMOS)
+}
+
+// NewDisassemblerForVariant initializes a new Disassembler with the target
+// program, decoding opcodes for the given CPUVariant.
+func NewDisassemblerForVariant(program []byte, variant CPUVariant) *Disassembler {
+	return NewDisassemblerForVariantWithIllegals(program, variant, IllegalsNone)
+}
+
+// NewDisassemblerForVariantWithIllegals initializes a new Disassembler with
+// the target program, decoding opcodes for the given CPUVariant plus the
+// NMOS illegal opcodes selected by illegals (ignored unless variant is
+// CPUNMOS). Use this instead of NewDisassemblerForVariant to disassemble
+// copy-protected tape loaders or demos that rely on illegal opcodes.
+func NewDisassemblerForVariantWithIllegals(program []byte, variant CPUVariant, illegals IllegalsMode) *Disassembler {
 	return &Disassembler{
 		Program:       program,
+		Variant:       variant,
+		Illegals:      illegals,
+		opCodes:       OpCodeSetWithIllegals(variant, illegals),
+		Symbols:       DefaultSymbolTable(MachineBBCB),
+		Dialect:       BeebasmDialect{},
 		usedOSAddress: make(map[uint]bool),
 		usedOSVector:  make(map[uint]bool),
 		vars:          make(map[string]varDef),
@@ -98,7 +172,14 @@ func (d *Disassembler) walk(vm visitMask, fn func(cursor uint, codeAddrIdx int,
 		// All instructions are at least one byte long and the first byte is
 		// sufficient to identify the opcode.
 		b := d.Program[cursor]
-		op, opOk := OpCodesMap[b]
+		op, opOk := d.opCodes[b]
+
+		// If Trace has run, only decode bytes its control-flow pass actually
+		// reached; everything else is forced to the data path below, however
+		// opcode-like its first byte happens to look.
+		if opOk && d.tracedRegions != nil && d.tracedRegions[cursor+d.BranchAdjust] != RegionCode {
+			opOk = false
+		}
 
 		// If the decoded 'instruction' straddles a code address then treat it
 		// as data.
@@ -115,11 +196,23 @@ func (d *Disassembler) walk(vm visitMask, fn func(cursor uint, codeAddrIdx int,
 	}
 }
 
-// Disassemble a 6502 program into a textual representation written to w
+// Disassemble a 6502 program into a textual representation written to w.
 // offset is where disassembly starts from the beginning of program.
 // branchAdjust is used to adjust the target address of relative branches to a
 // 'meaningful' address, typically the load address of the program.
-func (d *Disassembler) Disassemble(w io.Writer) {
+//
+// It also returns the decoded []Instruction stream backing that text, so
+// that the pipeline bytes -> []Instruction -> (text | bytes | json) is
+// available to callers that want a structured form rather than (or as well
+// as) the beebasm text: see bbcasm.go's Assemble, which reconstructs the
+// original bytes from this slice, and json.go's EncodeJSON, which renders it
+// as newline-delimited JSON for tools that don't want to parse beebasm
+// syntax.
+//
+// Its second return value is the []Diagnostic AnalyzeRegisters found, if
+// d.Warnings is set (nil otherwise); each is also inlined into the text as a
+// "WARN:" comment at the address it concerns.
+func (d *Disassembler) Disassemble(w io.Writer) ([]Instruction, []Diagnostic) {
 	if len(d.CodeAddrs) > 0 {
 		sort.Slice(d.CodeAddrs, func(i, j int) bool { return d.CodeAddrs[i] < d.CodeAddrs[j] })
 
@@ -132,26 +225,32 @@ func (d *Disassembler) Disassemble(w io.Writer) {
 	// will be marked as labels in the output.
 	d.findBranchTargets()
 
-	distem, _ := template.New("disasm").Parse(disasmHeader)
-	data := struct {
-		UsedOSAddress map[uint]bool
-		OSAddress     map[uint]string
-		UsedOSVector  map[uint]bool
-		OSVector      map[uint]string
-		Vars          map[string]varDef
-		LoadAddr      uint
-	}{d.usedOSAddress, addressToOsCallName, d.usedOSVector, osVectorAddresses, d.vars, d.BranchAdjust}
-	if err := distem.Execute(w, data); err != nil {
-		panic(err)
+	var diagnostics []Diagnostic
+	warnings := make(map[uint][]string)
+	if d.Warnings {
+		diagnostics = d.AnalyzeRegisters()
+		for _, diag := range diagnostics {
+			warnings[diag.Addr] = append(warnings[diag.Addr], diag.Message)
+		}
 	}
 
+	d.writeHeader(w)
+
 	// Second pass through program is to decode each instruction
 	// and print to stdout.
+	var instructions []Instruction
 	d.walk(vtAll, func(cursor uint, codeAddrIdx int, b byte, op Opcode, opOk bool) int {
 		var sb strings.Builder
+		var label string
+		for _, msg := range warnings[cursor+d.BranchAdjust] {
+			sb.WriteString(d.Dialect.CommentPrefix())
+			sb.WriteString(" WARN: ")
+			sb.WriteString(msg)
+			sb.WriteString("\n")
+		}
 		if targetIdx, ok := d.branchTargets[cursor+d.BranchAdjust]; ok {
-			sb.WriteByte('.')
-			sb.WriteString(fmt.Sprintf(labelFormatString, targetIdx))
+			label = fmt.Sprintf(labelFormatString, targetIdx)
+			sb.WriteString(d.Dialect.LabelDef(label))
 			sb.WriteString("\n")
 			w.Write([]byte(sb.String()))
 
@@ -187,6 +286,8 @@ func (d *Disassembler) Disassemble(w io.Writer) {
 				// If here then documented instruction that will assemble correctly
 				d.printInstruction(&sb, op, instruction, cursor)
 
+				instructions = append(instructions, newInstruction(cursor+d.BranchAdjust, op, instruction, label))
+
 				advance = op.Length
 			} else {
 				// The opcode was unrecognized, the opcode belongs to an
@@ -204,7 +305,7 @@ func (d *Disassembler) Disassemble(w io.Writer) {
 				// Include data bytes in comment section for visual consistency
 				// if the instruction is documented. Non documented instructions
 				// will print something else.
-				printData(&sb, instruction, doc, cursor+d.BranchAdjust)
+				d.printData(&sb, instruction, doc, cursor+d.BranchAdjust)
 
 				if !doc {
 					// Undocumented instruction includes additional info before printable bytes
@@ -216,12 +317,24 @@ func (d *Disassembler) Disassemble(w io.Writer) {
 
 				appendPrintableBytes(&sb, instruction)
 
+				instructions = append(instructions, newDataInstruction(cursor+d.BranchAdjust, instruction, label))
+
 				advance = uint(len(instruction))
 			}
+		} else if run := d.scanStringRun(cursor, codeAddrIdx); len(run) >= 4 {
+			d.printString(&sb, run, cursor+d.BranchAdjust)
+			appendPrintableBytes(&sb, run)
+
+			instructions = append(instructions, newDataInstruction(cursor+d.BranchAdjust, run, label))
+
+			advance = uint(len(run))
 		} else {
 			bs := []byte{b}
-			printData(&sb, bs, true, cursor+d.BranchAdjust)
+			d.printData(&sb, bs, true, cursor+d.BranchAdjust)
 			appendPrintableBytes(&sb, bs)
+
+			instructions = append(instructions, newDataInstruction(cursor+d.BranchAdjust, bs, label))
+
 			advance = 1
 		}
 
@@ -230,6 +343,8 @@ func (d *Disassembler) Disassemble(w io.Writer) {
 
 		return int(advance)
 	})
+
+	return instructions, diagnostics
 }
 
 func (d *Disassembler) printInstruction(sb *strings.Builder, op Opcode, instruction []byte, cursor uint) {
@@ -242,10 +357,11 @@ func (d *Disassembler) printInstruction(sb *strings.Builder, op Opcode, instruct
 	sb.WriteString(d.decode(op, instruction, cursor))
 
 	appendSpaces(sb, max(24-sb.Len(), 1))
-	sb.WriteString("\\ ")
+	sb.WriteString(d.Dialect.CommentPrefix())
+	sb.WriteByte(' ')
 
 	out := []string{
-		fmt.Sprintf("&%04X", cursor+d.BranchAdjust),
+		d.Dialect.HexWord(cursor + d.BranchAdjust),
 	}
 	for _, i := range instruction {
 		out = append(out, fmt.Sprintf("%02X", i))
@@ -253,27 +369,35 @@ func (d *Disassembler) printInstruction(sb *strings.Builder, op Opcode, instruct
 	sb.WriteString(strings.Join(out, " "))
 
 	appendPrintableBytes(sb, instruction)
+
+	if d.ShowCycles {
+		sb.WriteString(" ; ")
+		sb.WriteString(cycleComment(op, instruction, cursor, d.BranchAdjust, d.Variant))
+	}
 }
 
-// Print data in hex as comma-delimited EQUB statement. Assumes that there are
-// between 1 and 3 data bytes though it will handle any amount.
+// printData prints data in hex as a comma-delimited data-directive statement
+// in d.Dialect's syntax. Assumes that there are between 1 and 3 data bytes
+// though it will handle any amount.
 // If bytesInComment is true then the data byte values will be repeated in the
 // comment section.
-func printData(sb *strings.Builder, data []byte, bytesInComment bool, address uint) {
+func (d *Disassembler) printData(sb *strings.Builder, data []byte, bytesInComment bool, address uint) {
 	// Data will be printed to a line with format
 	// EQUB &[byte],...,&[byte]    \ [address] [byte] ... [byte] [printable bytes]
 	//                             ^--- 25th column              ^--- 45th column
 	var out []string
 	for _, i := range data {
-		out = append(out, fmt.Sprintf("&%02X", i))
+		out = append(out, d.Dialect.HexByte(i))
 	}
-	sb.WriteString("EQUB ")
+	sb.WriteString(d.Dialect.DataDirective())
+	sb.WriteByte(' ')
 	sb.WriteString(strings.Join(out, ","))
 
 	appendSpaces(sb, max(24-sb.Len(), 1))
-	sb.WriteString("\\ ")
+	sb.WriteString(d.Dialect.CommentPrefix())
+	sb.WriteByte(' ')
 
-	out = []string{fmt.Sprintf("&%04X", address)}
+	out = []string{d.Dialect.HexWord(address)}
 	if bytesInComment {
 		for _, i := range data {
 			out = append(out, fmt.Sprintf("%02X", i))
@@ -283,6 +407,50 @@ func printData(sb *strings.Builder, data []byte, bytesInComment bool, address ui
 	sb.WriteByte(' ')
 }
 
+// printString writes bs, a run of printable ASCII bytes found by
+// scanStringRun, as a string-directive statement in d.Dialect's syntax
+// instead of one data byte per element.
+func (d *Disassembler) printString(sb *strings.Builder, bs []byte, address uint) {
+	// EQUS "[string]"             \ [address] [printable bytes]
+	//                             ^--- 25th column                ^--- 45th column
+	sb.WriteString(d.Dialect.StringDirective())
+	sb.WriteByte(' ')
+	sb.WriteString(fmt.Sprintf("%q", string(bs)))
+
+	appendSpaces(sb, max(24-sb.Len(), 1))
+	sb.WriteString(d.Dialect.CommentPrefix())
+	sb.WriteByte(' ')
+	sb.WriteString(d.Dialect.HexWord(address))
+	sb.WriteByte(' ')
+}
+
+// scanStringRun looks for a run of printable ASCII bytes starting at cursor,
+// so that raw string data (e.g. error messages, filenames) disassembles as
+// an EQUS rather than a EQUB per byte. The run stops before the first
+// non-printable byte, a NUL or CR terminator (not included in the result),
+// the end of the program, the next CodeAddrs boundary, or the next labeled
+// address. Callers should fall back to printData for a result under 4
+// bytes, bbcdisasm's threshold for "probably a string".
+func (d *Disassembler) scanStringRun(cursor uint, codeAddrIdx int) []byte {
+	limit := d.Offset + d.MaxBytes
+	if codeAddrIdx < len(d.CodeAddrs) && d.CodeAddrs[codeAddrIdx] < limit {
+		limit = d.CodeAddrs[codeAddrIdx]
+	}
+
+	i := cursor
+	for i < limit {
+		b := d.Program[i]
+		if b == 0 || b == 13 || b < 0x20 || b > 0x7E {
+			break
+		}
+		if _, ok := d.branchTargets[i+d.BranchAdjust]; ok && i != cursor {
+			break
+		}
+		i++
+	}
+	return d.Program[cursor:i]
+}
+
 func appendSpaces(sb *strings.Builder, ns int) {
 	sb.Write(bytes.Repeat([]byte{' '}, ns))
 }
@@ -334,12 +502,28 @@ func willAssembleIdentically(op Opcode, instruction []byte) bool {
 	return true
 }
 
+func (d *Disassembler) genAbsoluteOsCall(bytes []byte) string {
+	addr := (uint(bytes[2]) << 8) + uint(bytes[1])
+
+	// Check if it is a well known OS address
+	if osCall, ok := d.Symbols.Resolve(addr, SymOSCall); ok {
+		return osCall
+	}
+
+	// Check if it is a known branch target
+	if tgtIdx, ok := d.branchTargets[addr]; ok {
+		return fmt.Sprintf(labelFormatString, tgtIdx)
+	}
+
+	return d.Dialect.HexWord(addr)
+}
+
 func (d *Disassembler) decode(op Opcode, bytes []byte, cursor uint) string {
 	// Jump and Branch instructions have special handling
 	if bytes[0] == OpJMPAbsolute || bytes[0] == OpJSRAbsolute {
 		// JMP &1234 and JSR &1234 are special cased with naming for well known
 		// OS call entry points.
-		return genAbsoluteOsCall(bytes, d.branchTargets)
+		return d.genAbsoluteOsCall(bytes)
 	}
 	if op.branchOrJump() == btBranch {
 		return genBranch(bytes, cursor, d.BranchAdjust, d.branchTargets, op.Length)
@@ -351,17 +535,17 @@ func (d *Disassembler) decode(op Opcode, bytes []byte, cursor uint) string {
 	case Accumulator:
 		return "A"
 	case Immediate:
-		return fmt.Sprintf("#&%02X", bytes[1])
+		return d.Dialect.ImmediatePrefix() + d.Dialect.HexByte(bytes[1])
 	case Absolute:
 		val := (uint(bytes[2]) << 8) + uint(bytes[1])
 
 		// Look up in the OS vector address space
-		if osv, ok := osVectorAddresses[val]; ok {
+		if osv, ok := d.Symbols.Resolve(val, SymVector); ok {
 			return osv
 		}
 		// Try again with the bottom bit cleared because each vector is 16-bit
 		// eg. USERV vector is at 0x200 and 0x201.
-		if osv, ok := osVectorAddresses[val&^uint(1)]; ok {
+		if osv, ok := d.Symbols.Resolve(val&^uint(1), SymVector); ok {
 			return osv + "+1"
 		}
 
@@ -370,60 +554,84 @@ func (d *Disassembler) decode(op Opcode, bytes []byte, cursor uint) string {
 		}
 
 		// Unrecognized address, return as numeric
-		return fmt.Sprintf("&%04X", val)
+		return d.Dialect.HexWord(val)
 	case ZeroPage:
+		if zp, ok := d.Symbols.Resolve(uint(bytes[1]), SymZeroPage); ok {
+			return zp
+		}
 		if dvar, ok := d.lookupVar(uint(bytes[1])); ok {
 			return dvar
 		}
-		return fmt.Sprintf("&%02X", bytes[1])
+		return d.Dialect.HexByte(bytes[1])
 	case ZeroPageX:
+		if zp, ok := d.Symbols.Resolve(uint(bytes[1]), SymZeroPage); ok {
+			return zp + ",X"
+		}
 		if dvar, ok := d.lookupVar(uint(bytes[1])); ok {
 			return dvar + ",X"
 		}
-		return fmt.Sprintf("&%02X,X", bytes[1])
+		return d.Dialect.HexByte(bytes[1]) + ",X"
 	case ZeroPageY:
+		if zp, ok := d.Symbols.Resolve(uint(bytes[1]), SymZeroPage); ok {
+			return zp + ",Y"
+		}
 		if dvar, ok := d.lookupVar(uint(bytes[1])); ok {
 			return dvar + ",Y"
 		}
-		return fmt.Sprintf("&%02X,Y", bytes[1])
+		return d.Dialect.HexByte(bytes[1]) + ",Y"
 	case ZeroPageRel:
 		if dvar, ok := d.lookupVar(uint(bytes[1])); ok {
 			return fmt.Sprintf("%s, #%02x", dvar, bytes[2])
 		}
-		return fmt.Sprintf("&%02X,#%02X", bytes[1], bytes[2])
+		return d.Dialect.HexByte(bytes[1]) + "," + d.Dialect.ImmediatePrefix() + fmt.Sprintf("%02X", bytes[2])
 	case Indirect:
 		val := (uint(bytes[2]) << 8) + uint(bytes[1])
 		if dvar, ok := d.lookupVar(val); ok {
 			return "(" + dvar + ")"
 		}
-		return fmt.Sprintf("(&%04X)", val)
+		return "(" + d.Dialect.HexWord(val) + ")"
 	case AbsoluteX:
 		val := (uint(bytes[2]) << 8) + uint(bytes[1])
 		if dvar, ok := d.lookupVar(val); ok {
 			return dvar + ",X"
 		}
-		return fmt.Sprintf("&%04X,X", val)
+		return d.Dialect.HexWord(val) + ",X"
 	case AbsoluteY:
 		val := (uint(bytes[2]) << 8) + uint(bytes[1])
 		if dvar, ok := d.lookupVar(val); ok {
 			return dvar + ",Y"
 		}
-		return fmt.Sprintf("&%04X,Y", val)
+		return d.Dialect.HexWord(val) + ",Y"
 	case IndirectX:
+		if zp, ok := d.Symbols.Resolve(uint(bytes[1]), SymZeroPage); ok {
+			return "(" + zp + ",X)"
+		}
 		if dvar, ok := d.lookupVar(uint(bytes[1])); ok {
 			return "(" + dvar + ",X)"
 		}
-		return fmt.Sprintf("(&%02X,X)", bytes[1])
+		return "(" + d.Dialect.HexByte(bytes[1]) + ",X)"
 	case IndirectY:
+		if zp, ok := d.Symbols.Resolve(uint(bytes[1]), SymZeroPage); ok {
+			return "(" + zp + "),Y"
+		}
 		if dvar, ok := d.lookupVar(uint(bytes[1])); ok {
 			return "(" + dvar + "),Y"
 		}
-		return fmt.Sprintf("(&%02X),Y", bytes[1])
+		return "(" + d.Dialect.HexByte(bytes[1]) + "),Y"
 	case IndirectZP:
+		if zp, ok := d.Symbols.Resolve(uint(bytes[1]), SymZeroPage); ok {
+			return "(" + zp + ")"
+		}
 		if dvar, ok := d.lookupVar(uint(bytes[1])); ok {
 			return "(" + dvar + ")"
 		}
-		return fmt.Sprintf("(&%02X)", bytes[1])
+		return "(" + d.Dialect.HexByte(bytes[1]) + ")"
+	case IndirectAbsoluteX:
+		val := (uint(bytes[2]) << 8) + uint(bytes[1])
+		if dvar, ok := d.lookupVar(val); ok {
+			return "(" + dvar + ",X)"
+		}
+		return "(" + d.Dialect.HexWord(val) + ",X)"
 	default:
 		return "UNKNOWN ADDRESS MODE"
 	}
@@ -452,32 +660,20 @@ func (d *Disassembler) findBranchTargets() {
 			instruction := d.Program[cursor : cursor+op.Length]
 			switch op.branchOrJump() {
 			case btBranch:
-				// This is ugly but it will do for now
-				boff := int(instruction[1]) // All branches are 2 bytes long
-				if op.Length == 3 {
-					boff = int(instruction[2])
-				}
-				if boff > 127 {
-					boff = boff - 256
-				}
-				// Adjust d.Offset to account for the 2 byte behavior, see
-				// genBranch().
-				boff += int(op.Length)
-
-				tgt := cursor + uint(boff) + d.BranchAdjust
+				tgt := branchTarget(instruction, cursor, d.BranchAdjust, op.Length)
 				if _, ok := d.branchTargets[tgt]; !ok {
 					d.branchTargets[tgt] = 0 // value will be filled out later
 				}
 			case btJump:
-				// Skip indirect jump since we don't know the target of the jump
-				if b != OpJMPIndirect {
+				// Skip indirect jumps since we don't know the target of the jump
+				if b != OpJMPIndirect && b != OpJMPIndirectAbsoluteX {
 					tgt := (uint(instruction[2]) << 8) + uint(instruction[1])
 					if _, ok := d.branchTargets[tgt]; !ok {
 						d.branchTargets[tgt] = 0 // value will be filled out later
 					}
 
 					// If the jump target is a well known OS call then mark as seen
-					if _, ok := addressToOsCallName[tgt]; ok {
+					if _, ok := d.Symbols.Resolve(tgt, SymOSCall); ok {
 						d.usedOSAddress[tgt] = true
 					}
 				}
@@ -485,7 +681,7 @@ func (d *Disassembler) findBranchTargets() {
 				// Check instructions with Absolute addressing
 				if op.AddrMode == Absolute {
 					tgt := (uint(instruction[2]) << 8) + uint(instruction[1])
-					if _, ok := osVectorAddresses[tgt]; ok {
+					if _, ok := d.Symbols.Resolve(tgt, SymVector); ok {
 						d.usedOSVector[tgt] = true
 					}
 				}
@@ -520,6 +716,87 @@ func (d *Disassembler) findBranchTargets() {
 	}
 }
 
+// writeHeader writes the disassembly's banner comment, OS call/vector/var
+// cross-reference and origin directive to w. BeebasmDialect keeps using the
+// original disasmHeader template verbatim (preserving bbcdisasm's long
+// standing output byte-for-byte); every other dialect renders the same
+// information generically from SyntaxDialect's primitives, since the
+// template's beebasm-specific "CODE% = ..." / "ORG CODE%" idiom has no
+// equivalent in ca65/ACME/DASM syntax.
+func (d *Disassembler) writeHeader(w io.Writer) {
+	if _, ok := d.Dialect.(BeebasmDialect); ok {
+		distem, _ := template.New("disasm").Parse(disasmHeader)
+		data := struct {
+			UsedOSAddress map[uint]bool
+			OSAddress     map[uint]string
+			UsedOSVector  map[uint]bool
+			OSVector      map[uint]string
+			Vars          map[string]varDef
+			LoadAddr      uint
+		}{d.usedOSAddress, d.Symbols.All(SymOSCall), d.usedOSVector, d.Symbols.All(SymVector), d.vars, d.BranchAdjust}
+		if err := distem.Execute(w, data); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	c := d.Dialect.CommentPrefix()
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s This disassembly was produced by bbcdisasm\n\n", c)
+
+	if len(d.usedOSAddress) > 0 {
+		fmt.Fprintf(&sb, "%s OS Call Addresses\n", c)
+		names := d.Symbols.All(SymOSCall)
+		for _, addr := range sortedKeys(d.usedOSAddress) {
+			fmt.Fprintf(&sb, "%-6s = %s\n", names[addr], d.Dialect.HexWord(addr))
+		}
+		sb.WriteByte('\n')
+	}
+	if len(d.usedOSVector) > 0 {
+		fmt.Fprintf(&sb, "%s OS Vector Addresses\n", c)
+		names := d.Symbols.All(SymVector)
+		for _, addr := range sortedKeys(d.usedOSVector) {
+			fmt.Fprintf(&sb, "%-5s = %s\n", names[addr], d.Dialect.HexWord(addr))
+		}
+		sb.WriteByte('\n')
+	}
+	if len(d.vars) > 0 {
+		fmt.Fprintf(&sb, "%s Defined Variables\n", c)
+		for _, name := range sortedVarNames(d.vars) {
+			fmt.Fprintf(&sb, "%-5s = %s\n", name, d.vars[name].Sval)
+		}
+		sb.WriteByte('\n')
+	}
+	if d.BranchAdjust != 0 {
+		fmt.Fprintf(&sb, "%s %s\n\n", d.Dialect.OriginDirective(), d.Dialect.HexWord(d.BranchAdjust))
+	}
+
+	w.Write([]byte(sb.String()))
+}
+
+// sortedKeys returns m's keys (addresses that were actually used) in
+// increasing order, so writeHeader's generic path is deterministic like the
+// beebasm template (text/template sorts map keys in range).
+func sortedKeys(m map[uint]bool) []uint {
+	keys := make([]uint, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// sortedVarNames returns vars' names in alphabetical order, for the same
+// reason as sortedKeys.
+func sortedVarNames(vars map[string]varDef) []string {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 var disasmHeader = `\ ******************************************************************************
 \
 \ This disassembly was produced by bbcdisasm
@@ -0,0 +1,103 @@
+package bbcdisasm
+
+// Instruction is a single decoded unit of a disassembled program: either a
+// real 6502 instruction (Opcode.Name != "") or a run of raw bytes that
+// Disassemble chose to emit as data (Opcode is the zero Opcode in that
+// case, and Bytes holds the raw values).
+//
+// Bytes always holds the exact original program bytes this Instruction was
+// decoded from, so concatenating the Bytes of every Instruction returned by
+// Disassemble reproduces the input program exactly; Operand carries the
+// instruction's operand as an unresolved numeric value (e.g. a branch's raw
+// relative offset, not its resolved target) so that an Instruction built by
+// hand, without Bytes, can still be encoded by EncodeInstruction.
+type Instruction struct {
+	Addr    uint   // Load address, i.e. with BranchAdjust already applied
+	Bytes   []byte // The exact original bytes this instruction decoded from
+	Opcode  Opcode // The zero Opcode for a data Instruction
+	Operand uint   // Unresolved operand value; meaning depends on Opcode.AddrMode
+	Label   string // Label assigned to Addr, without the leading '.'; "" if none
+}
+
+// IsData reports whether this Instruction is a run of raw data bytes rather
+// than a decoded 6502 instruction.
+func (i Instruction) IsData() bool {
+	return i.Opcode.Name == ""
+}
+
+// IsBranch reports whether this is a relative branch instruction (e.g. BNE,
+// BBR0), as opposed to an absolute JMP/JSR or a non-control-flow opcode.
+func (i Instruction) IsBranch() bool {
+	return !i.IsData() && i.Opcode.branchOrJump() == btBranch
+}
+
+// IsUndocumented reports whether this is one of the NMOS 6502's undocumented
+// opcodes (see UndocumentedInstructions). Disassemble returns every
+// undocumented opcode, ANC included, as a data Instruction, so this is false
+// for every Instruction it returns.
+func (i Instruction) IsUndocumented() bool {
+	return !i.IsData() && !isOpcodeDocumented(i.Opcode)
+}
+
+// TargetAddr returns the absolute address a branch or JMP/JSR instruction
+// refers to, and true if i is such an instruction. The address is resolved
+// purely from i's own bytes, so - unlike the decoder's genAbsoluteOsCall -
+// it is returned numerically even when it also happens to be a well-known OS
+// call or vector address.
+func (i Instruction) TargetAddr() (uint, bool) {
+	if i.IsData() {
+		return 0, false
+	}
+	switch i.Opcode.branchOrJump() {
+	case btBranch:
+		return branchTarget(i.Bytes, i.Addr, 0, i.Opcode.Length), true
+	case btJump:
+		if i.Bytes[0] == OpJMPIndirect || i.Bytes[0] == OpJMPIndirectAbsoluteX {
+			return 0, false
+		}
+		return i.Operand, true
+	default:
+		return 0, false
+	}
+}
+
+func newInstruction(addr uint, op Opcode, raw []byte, label string) Instruction {
+	bs := append([]byte{}, raw...)
+	return Instruction{
+		Addr:    addr,
+		Bytes:   bs,
+		Opcode:  op,
+		Operand: operandOf(op, bs),
+		Label:   label,
+	}
+}
+
+func newDataInstruction(addr uint, raw []byte, label string) Instruction {
+	return Instruction{
+		Addr:  addr,
+		Bytes: append([]byte{}, raw...),
+		Label: label,
+	}
+}
+
+// operandOf extracts op's operand as it's encoded in raw, without resolving
+// it against any symbol table, variable, or branch target.
+func operandOf(op Opcode, raw []byte) uint {
+	switch op.AddrMode {
+	case None, Accumulator:
+		switch op.Length {
+		case 2: // Branch or BRA: one raw relative-offset byte
+			return uint(raw[1])
+		case 3: // BBRn/BBSn: zero page address, then relative offset
+			return uint(raw[1]) | uint(raw[2])<<8
+		}
+		return 0
+	case Absolute, AbsoluteX, AbsoluteY, Indirect, IndirectAbsoluteX:
+		return uint(raw[1]) | uint(raw[2])<<8
+	case ZeroPageRel:
+		return uint(raw[1]) | uint(raw[2])<<8
+	default:
+		// Immediate, ZeroPage(X/Y), Indirect(X/Y), IndirectZP: one operand byte
+		return uint(raw[1])
+	}
+}
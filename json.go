@@ -0,0 +1,57 @@
+package bbcdisasm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// jsonInstruction is the wire format EncodeJSON writes one of per line: a
+// flattened, self-describing view of an Instruction for downstream tools
+// (patchers, cross-referencers, coverage viewers) that want to consume
+// disassembly without regexing the beebasm text.
+type jsonInstruction struct {
+	Addr           uint   `json:"addr"`
+	Bytes          []byte `json:"bytes"`
+	Kind           string `json:"kind"` // "code" or "data"
+	Mnemonic       string `json:"mnemonic,omitempty"`
+	AddrMode       string `json:"addr_mode,omitempty"`
+	Operand        uint   `json:"operand,omitempty"`
+	TargetAddr     *uint  `json:"target_addr,omitempty"`
+	IsBranch       bool   `json:"is_branch,omitempty"`
+	IsUndocumented bool   `json:"is_undocumented,omitempty"`
+	Label          string `json:"label,omitempty"`
+}
+
+// EncodeJSON writes instructions, as returned by Disassemble, to w as
+// newline-delimited JSON: one jsonInstruction object per line, in order.
+// This is the structured sibling of the beebasm text Disassemble also
+// writes, extending the bytes -> []Instruction -> (text | bytes) pipeline
+// described on Disassemble with a third leg: bytes -> []Instruction -> json.
+func EncodeJSON(instructions []Instruction, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for i, ins := range instructions {
+		ji := jsonInstruction{
+			Addr:  ins.Addr,
+			Bytes: ins.Bytes,
+			Kind:  "data",
+			Label: ins.Label,
+		}
+		if !ins.IsData() {
+			ji.Kind = "code"
+			ji.Mnemonic = ins.Opcode.Name
+			ji.AddrMode = ins.Opcode.AddrMode.String()
+			ji.Operand = ins.Operand
+			ji.IsBranch = ins.IsBranch()
+			ji.IsUndocumented = ins.IsUndocumented()
+			if tgt, ok := ins.TargetAddr(); ok {
+				ji.TargetAddr = &tgt
+			}
+		}
+
+		if err := enc.Encode(ji); err != nil {
+			return fmt.Errorf("bbcdisasm: instruction %d at &%04X: %w", i, ins.Addr, err)
+		}
+	}
+	return nil
+}
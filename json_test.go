@@ -0,0 +1,60 @@
+package bbcdisasm
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+// TestEncodeJSON checks that EncodeJSON emits one correctly-populated
+// jsonInstruction line per Instruction, for both a decoded instruction and a
+// run of data bytes.
+func TestEncodeJSON(t *testing.T) {
+	// 0x4C 0x00 0x02: JMP &0200, followed by one undecodable byte (0xFF is
+	// illegal on NMOS with IllegalsNone) that falls back to data.
+	program := []byte{0x4C, 0x00, 0x02, 0xFF}
+	d := NewDisassemblerForVariant(program, CPUNMOS)
+	d.MaxBytes = uint(len(program))
+	var buf bytes.Buffer
+	instructions, diagnostics := d.Disassemble(&buf)
+	if diagnostics != nil {
+		t.Fatalf("Disassemble: unexpected diagnostics %v", diagnostics)
+	}
+
+	var out bytes.Buffer
+	if err := EncodeJSON(instructions, &out); err != nil {
+		t.Fatalf("EncodeJSON: %v", err)
+	}
+
+	dec := json.NewDecoder(&out)
+	var lines []jsonInstruction
+	for dec.More() {
+		var ji jsonInstruction
+		if err := dec.Decode(&ji); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		lines = append(lines, ji)
+	}
+	if len(lines) != len(instructions) {
+		t.Fatalf("got %d JSON lines, want %d (one per Instruction)", len(lines), len(instructions))
+	}
+
+	jmp := lines[0]
+	if jmp.Kind != "code" || jmp.Mnemonic != "JMP" || jmp.AddrMode != "Absolute" {
+		t.Errorf("JMP line: got %+v", jmp)
+	}
+	if jmp.TargetAddr == nil || *jmp.TargetAddr != 0x0200 {
+		t.Errorf("JMP line: got TargetAddr %v, want &0200", jmp.TargetAddr)
+	}
+	if jmp.IsBranch {
+		t.Errorf("JMP line: IsBranch = true, want false")
+	}
+
+	data := lines[1]
+	if data.Kind != "data" || data.Mnemonic != "" {
+		t.Errorf("data line: got %+v", data)
+	}
+	if !bytes.Equal(data.Bytes, []byte{0xFF}) {
+		t.Errorf("data line: got Bytes % X, want FF", data.Bytes)
+	}
+}
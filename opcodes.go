@@ -10,20 +10,22 @@ const labelFormatString = "label_%d"
 type AddressingMode int
 
 // Addressing Modes
-//  None        - no addressing mode                          - BRK
-//  Accumulator - uses the accumulator register               - ASL A
-//  Immediate   - using a data constant                       - LDA #FF
-//  Absolute    - using a fixed address                       - LDA &1234
-//  ZeroPage    - using a fixed zero page address             - LDA &12
-//  ZeroPageX   - using zero page address+X                   - LDA &12,X
-//  ZeroPageY   - using zero page address+Y (LDX only)        - LDX &12,Y
-//  ZeroPageRel - using zero page address+immediate value     - BBR6 &12, #34
-//  Indirect    - using an address stored in memory           - LDA (&1234)
-//  AbsoluteX   - using an absolute address+X                 - LDA &1234,X
-//  AbsoluteY   - using an absolute address+Y                 - LDA &1234,Y
-//  IndirectX   - a table of zero page addresses indexed by X - LDA (&80,X)
-//  IndirectY   - a table of zero page addresses indexed by Y - LDA (&80,Y)
-//  IndirectZP  - An indirect zero page address
+//
+//	None        - no addressing mode                          - BRK
+//	Accumulator - uses the accumulator register               - ASL A
+//	Immediate   - using a data constant                       - LDA #FF
+//	Absolute    - using a fixed address                       - LDA &1234
+//	ZeroPage    - using a fixed zero page address             - LDA &12
+//	ZeroPageX   - using zero page address+X                   - LDA &12,X
+//	ZeroPageY   - using zero page address+Y (LDX only)        - LDX &12,Y
+//	ZeroPageRel - using zero page address+immediate value     - BBR6 &12, #34
+//	Indirect    - using an address stored in memory           - JMP (&1234)
+//	AbsoluteX   - using an absolute address+X                 - LDA &1234,X
+//	AbsoluteY   - using an absolute address+Y                 - LDA &1234,Y
+//	IndirectX   - a table of zero page addresses indexed by X - LDA (&80,X)
+//	IndirectY   - a table of zero page addresses indexed by Y - LDA (&80),Y
+//	IndirectZP  - An indirect zero page address
+//	IndirectAbsoluteX - an absolute address+X, indirected      - JMP (&1234,X)
 const (
 	None AddressingMode = iota
 	Accumulator
@@ -39,280 +41,569 @@ const (
 	IndirectX
 	IndirectY
 	IndirectZP
+	IndirectAbsoluteX
 )
 
+// addrModeNames gives the String() representation of each AddressingMode,
+// used by the JSON output backend (see json.go) where a readable name is
+// more useful to a downstream tool than the bare int value.
+var addrModeNames = [...]string{
+	None:              "None",
+	Accumulator:       "Accumulator",
+	Immediate:         "Immediate",
+	Absolute:          "Absolute",
+	ZeroPage:          "ZeroPage",
+	ZeroPageX:         "ZeroPageX",
+	ZeroPageY:         "ZeroPageY",
+	ZeroPageRel:       "ZeroPageRel",
+	Indirect:          "Indirect",
+	AbsoluteX:         "AbsoluteX",
+	AbsoluteY:         "AbsoluteY",
+	IndirectX:         "IndirectX",
+	IndirectY:         "IndirectY",
+	IndirectZP:        "IndirectZP",
+	IndirectAbsoluteX: "IndirectAbsoluteX",
+}
+
+// String returns m's name, e.g. "ZeroPageX".
+func (m AddressingMode) String() string {
+	if int(m) < 0 || int(m) >= len(addrModeNames) {
+		return "Unknown"
+	}
+	return addrModeNames[m]
+}
+
 // Opcode defines a 6502 opcode
 type Opcode struct {
 	Value    byte   // Byte value for the opcode. All opcodes are one byte long.
 	Name     string // Human readable instruction 'name'
 	Length   uint   // Num bytes for instruction and arguments, includes opcode
 	AddrMode AddressingMode
+	Unstable bool // Undocumented NMOS opcode whose behaviour varies by chip revision/temperature
 }
 
 // TODO - Constants for all instructions?
 const (
-	OpJMPAbsolute = 0x4C
-	OpJMPIndirect = 0x6C
-	OpJSRAbsolute = 0x20
+	OpJMPAbsolute          = 0x4C
+	OpJMPIndirect          = 0x6C
+	OpJMPIndirectAbsoluteX = 0x7C
+	OpJSRAbsolute          = 0x20
 )
 
-var (
-	// OpCodes defines the documented instructions of the 6502 CPU. It also
-	// includes a couple of undocumented instructions.
-	// Most opcodes from http://www.6502.org/tutorials/6502opcodes.html
-	// ANC, SLO, SRE from https://github.com/mattgodbolt/jsbeeb/blob/master/6502.opcodes.js
-	OpCodes = []Opcode{
-		{0x69, "ADC", 2, Immediate},
-		{0x65, "ADC", 2, ZeroPage},
-		{0x75, "ADC", 2, ZeroPageX},
-		{0x6D, "ADC", 3, Absolute},
-		{0x7D, "ADC", 3, AbsoluteX},
-		{0x79, "ADC", 3, AbsoluteY},
-		{0x61, "ADC", 2, IndirectX},
-		{0x71, "ADC", 2, IndirectY},
-		{0x72, "ADC", 2, IndirectZP},
-
-		{0x0B, "ANC", 2, Immediate},
-		{0x2B, "ANC", 2, Immediate},
-
-		{0x29, "AND", 2, Immediate},
-		{0x25, "AND", 2, ZeroPage},
-		{0x35, "AND", 2, ZeroPageX},
-		{0x2D, "AND", 3, Absolute},
-		{0x3D, "AND", 3, AbsoluteX},
-		{0x39, "AND", 3, AbsoluteY},
-		{0x21, "AND", 2, IndirectX},
-		{0x31, "AND", 2, IndirectY},
-		{0x32, "AND", 2, IndirectZP},
-
-		{0x0A, "ASL", 1, Accumulator},
-		{0x06, "ASL", 2, ZeroPage},
-		{0x16, "ASL", 2, ZeroPageX},
-		{0x0E, "ASL", 3, Absolute},
-		{0x1E, "ASL", 3, AbsoluteX},
-
-		{0x0F, "BBR0", 3, None},
-		{0x1F, "BBR1", 3, None},
-		{0x2F, "BBR2", 3, None},
-		{0x3F, "BBR3", 3, None},
-		{0x4F, "BBR4", 3, None},
-		{0x5F, "BBR5", 3, None},
-		{0x6F, "BBR6", 3, None},
-		{0x7F, "BBR7", 3, None},
-		{0x8F, "BBS0", 3, None},
-		{0x9F, "BBS1", 3, None},
-		{0xAF, "BBS2", 3, None},
-		{0xBF, "BBS3", 3, None},
-		{0xCF, "BBS4", 3, None},
-		{0xDF, "BBS5", 3, None},
-		{0xEF, "BBS6", 3, None},
-		{0xFF, "BBS7", 3, None},
-
-		{0x24, "BIT", 2, ZeroPage},
-		{0x2C, "BIT", 3, Absolute},
-
-		{0x10, "BPL", 2, None}, // all the branch instructions have special cased
-		{0x30, "BMI", 2, None}, // printing
-		{0x50, "BVC", 2, None},
-		{0x70, "BVS", 2, None},
-		{0x80, "BRA", 2, None},
-		{0x90, "BCC", 2, None},
-		{0xB0, "BCS", 2, None},
-		{0xD0, "BNE", 2, None},
-		{0xF0, "BEQ", 2, None},
-
-		{0x00, "BRK", 1, None},
-
-		{0xC9, "CMP", 2, Immediate},
-		{0xC5, "CMP", 2, ZeroPage},
-		{0xD5, "CMP", 2, ZeroPageX},
-		{0xCD, "CMP", 3, Absolute},
-		{0xDD, "CMP", 3, AbsoluteX},
-		{0xD9, "CMP", 3, AbsoluteY},
-		{0xC1, "CMP", 2, IndirectX},
-		{0xD1, "CMP", 2, IndirectY},
-
-		{0xE0, "CPX", 2, Immediate},
-		{0xE4, "CPX", 2, ZeroPage},
-		{0xEC, "CPX", 3, Absolute},
-
-		{0xC0, "CPY", 2, Immediate},
-		{0xC4, "CPY", 2, ZeroPage},
-		{0xCC, "CPY", 3, Absolute},
-
-		{0xC6, "DEC", 2, ZeroPage},
-		{0xD6, "DEC", 2, ZeroPageX},
-		{0xCE, "DEC", 3, Absolute},
-		{0xDE, "DEC", 3, AbsoluteX},
-
-		{0x49, "EOR", 2, Immediate},
-		{0x45, "EOR", 2, ZeroPage},
-		{0x55, "EOR", 2, ZeroPageX},
-		{0x4D, "EOR", 3, Absolute},
-		{0x5D, "EOR", 3, AbsoluteX},
-		{0x59, "EOR", 3, AbsoluteY},
-		{0x41, "EOR", 2, IndirectX},
-		{0x51, "EOR", 2, IndirectY},
-
-		{0x18, "CLC", 1, None},
-		{0x38, "SEC", 1, None},
-		{0x58, "CLI", 1, None},
-		{0x78, "SEI", 1, None},
-		{0xB8, "CLV", 1, None},
-		{0xD8, "CLD", 1, None},
-		{0xF8, "SED", 1, None},
-
-		{0xE6, "INC", 2, ZeroPage},
-		{0xF6, "INC", 2, ZeroPageX},
-		{0xEE, "INC", 3, Absolute},
-		{0xFE, "INC", 3, AbsoluteX},
-
-		{OpJMPAbsolute, "JMP", 3, Absolute}, // special cased when printing
-		{OpJMPIndirect, "JMP", 3, Indirect},
-
-		{OpJSRAbsolute, "JSR", 3, Absolute}, // special cased when printing
-
-		{0xA9, "LDA", 2, Immediate},
-		{0xA5, "LDA", 2, ZeroPage},
-		{0xB5, "LDA", 2, ZeroPageX},
-		{0xAD, "LDA", 3, Absolute},
-		{0xBD, "LDA", 3, AbsoluteX},
-		{0xB9, "LDA", 3, AbsoluteY},
-		{0xA1, "LDA", 2, IndirectX},
-		{0xB1, "LDA", 2, IndirectY},
-
-		{0xA2, "LDX", 2, Immediate},
-		{0xA6, "LDX", 2, ZeroPage},
-		{0xB6, "LDX", 2, ZeroPageY},
-		{0xAE, "LDX", 3, Absolute},
-		{0xBE, "LDX", 3, AbsoluteY},
-
-		{0xA0, "LDY", 2, Immediate},
-		{0xA4, "LDY", 2, ZeroPage},
-		{0xB4, "LDY", 2, ZeroPageX},
-		{0xAC, "LDY", 3, Absolute},
-		{0xBC, "LDY", 3, AbsoluteX},
-
-		{0x4A, "LSR", 1, Accumulator},
-		{0x46, "LSR", 2, ZeroPage},
-		{0x56, "LSR", 2, ZeroPageX},
-		{0x4E, "LSR", 3, Absolute},
-		{0x5E, "LSR", 3, AbsoluteX},
-
-		{0xEA, "NOP", 1, None},
-
-		{0x09, "ORA", 2, Immediate},
-		{0x05, "ORA", 2, ZeroPage},
-		{0x15, "ORA", 2, ZeroPageX},
-		{0x0D, "ORA", 3, Absolute},
-		{0x1D, "ORA", 3, AbsoluteX},
-		{0x19, "ORA", 3, AbsoluteY},
-		{0x01, "ORA", 2, IndirectX},
-		{0x11, "ORA", 2, IndirectY},
-
-		{0xAA, "TAX", 1, None},
-		{0x8A, "TXA", 1, None},
-		{0xCA, "DEX", 1, None},
-		{0xE8, "INX", 1, None},
-		{0xA8, "TAY", 1, None},
-		{0x98, "TYA", 1, None},
-		{0x88, "DEY", 1, None},
-		{0xC8, "INY", 1, None},
-
-		{0x07, "RMB0", 2, ZeroPage},
-		{0x17, "RMB1", 2, ZeroPage},
-		{0x27, "RMB2", 2, ZeroPage},
-		{0x37, "RMB3", 2, ZeroPage},
-		{0x47, "RMB4", 2, ZeroPage},
-		{0x57, "RMB5", 2, ZeroPage},
-		{0x67, "RMB6", 2, ZeroPage},
-		{0x77, "RMB7", 2, ZeroPage},
-
-
-		{0x2A, "ROL", 1, Accumulator},
-		{0x26, "ROL", 2, ZeroPage},
-		{0x36, "ROL", 2, ZeroPageX},
-		{0x2E, "ROL", 3, Absolute},
-		{0x3E, "ROL", 3, AbsoluteX},
-
-		{0x6A, "ROR", 1, Accumulator},
-		{0x66, "ROR", 2, ZeroPage},
-		{0x76, "ROR", 2, ZeroPageX},
-		{0x6E, "ROR", 3, Absolute},
-		{0x7E, "ROR", 3, AbsoluteX},
-
-		{0x40, "RTI", 1, None},
-
-		{0x60, "RTS", 1, None},
-
-		{0xE9, "SBC", 2, Immediate},
-		{0xE5, "SBC", 2, ZeroPage},
-		{0xF5, "SBC", 2, ZeroPageX},
-		{0xED, "SBC", 3, Absolute},
-		{0xFD, "SBC", 3, AbsoluteX},
-		{0xF9, "SBC", 3, AbsoluteY},
-		{0xE1, "SBC", 2, IndirectX},
-		{0xF1, "SBC", 2, IndirectY},
-
-		{0x87, "SMB0", 2, ZeroPage},
-		{0x97, "SMB1", 2, ZeroPage},
-		{0xA7, "SMB2", 2, ZeroPage},
-		{0xB7, "SMB3", 2, ZeroPage},
-		{0xC7, "SMB4", 2, ZeroPage},
-		{0xD7, "SMB5", 2, ZeroPage},
-		{0xE7, "SMB6", 2, ZeroPage},
-		{0xF7, "SMB7", 2, ZeroPage},
-
-/*
-		{0x47, "SRE", 2, ZeroPage},
-		{0x57, "SRE", 2, ZeroPageX},
-		{0x4F, "SRE", 3, Absolute},
-		{0x5F, "SRE", 3, AbsoluteX},
-		{0x5B, "SRE", 3, AbsoluteY},
-		{0x43, "SRE", 2, IndirectX},
-		{0x53, "SRE", 2, IndirectY},
-*/
-
-		{0x85, "STA", 2, ZeroPage},
-		{0x95, "STA", 2, ZeroPageX},
-		{0x8D, "STA", 3, Absolute},
-		{0x9D, "STA", 3, AbsoluteX},
-		{0x99, "STA", 3, AbsoluteY},
-		{0x81, "STA", 2, IndirectX},
-		{0x91, "STA", 2, IndirectY},
-
-		{0x9A, "TXS", 1, None},
-		{0xBA, "TSX", 1, None},
-		{0x48, "PHA", 1, None},
-		{0x68, "PLA", 1, None},
-		{0x08, "PHP", 1, None},
-		{0x28, "PLP", 1, None},
-
-/*
-		{0x07, "SLO", 2, ZeroPage},
-		{0x17, "SLO", 2, ZeroPageX},
-		{0x0F, "SLO", 3, Absolute},
-		{0x1F, "SLO", 3, AbsoluteX},
-		{0x1B, "SLO", 3, AbsoluteY},
-		{0x03, "SLO", 2, IndirectX},
-		{0x13, "SLO", 2, IndirectY},
-*/
-
-		{0x86, "STX", 2, ZeroPage},
-		{0x96, "STX", 2, ZeroPageY},
-		{0x8E, "STX", 3, Absolute},
-
-		{0x84, "STY", 2, ZeroPage},
-		{0x94, "STY", 2, ZeroPageX},
-		{0x8C, "STY", 3, Absolute},
+// CPUVariant selects which member of the 6502 family to decode opcodes
+// against. The different BBC Micro family machines shipped different CPUs:
+// the Model B and Electron use the NMOS 6502, while the Master 128 uses the
+// Rockwell R65C02.
+type CPUVariant int
+
+// CPU Variants
+const (
+	CPUNMOS     CPUVariant = iota // NMOS 6502, as shipped in the Model B and Electron
+	CPU65C02                      // Generic CMOS 65C02
+	CPUR65C02                     // Rockwell R65C02, as shipped in the Master 128
+	CPUWDC65C02                   // WDC 65C02
+)
+
+// CPU65SC02 is an alias for CPU65C02: WDC's original 65SC02 part documented
+// the same instruction set as the generic CMOS 65C02 modelled here (no
+// Rockwell bit ops, no WAI/STP), so the two decode identically.
+const CPU65SC02 = CPU65C02
+
+// opCodesCommon defines the instructions whose opcode, length and addressing
+// mode are identical across every supported CPUVariant.
+var opCodesCommon = []Opcode{
+	{Value: 0x69, Name: "ADC", Length: 2, AddrMode: Immediate},
+	{Value: 0x65, Name: "ADC", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x75, Name: "ADC", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0x6D, Name: "ADC", Length: 3, AddrMode: Absolute},
+	{Value: 0x7D, Name: "ADC", Length: 3, AddrMode: AbsoluteX},
+	{Value: 0x79, Name: "ADC", Length: 3, AddrMode: AbsoluteY},
+	{Value: 0x61, Name: "ADC", Length: 2, AddrMode: IndirectX},
+	{Value: 0x71, Name: "ADC", Length: 2, AddrMode: IndirectY},
+
+	{Value: 0x29, Name: "AND", Length: 2, AddrMode: Immediate},
+	{Value: 0x25, Name: "AND", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x35, Name: "AND", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0x2D, Name: "AND", Length: 3, AddrMode: Absolute},
+	{Value: 0x3D, Name: "AND", Length: 3, AddrMode: AbsoluteX},
+	{Value: 0x39, Name: "AND", Length: 3, AddrMode: AbsoluteY},
+	{Value: 0x21, Name: "AND", Length: 2, AddrMode: IndirectX},
+	{Value: 0x31, Name: "AND", Length: 2, AddrMode: IndirectY},
+
+	{Value: 0x0A, Name: "ASL", Length: 1, AddrMode: Accumulator},
+	{Value: 0x06, Name: "ASL", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x16, Name: "ASL", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0x0E, Name: "ASL", Length: 3, AddrMode: Absolute},
+	{Value: 0x1E, Name: "ASL", Length: 3, AddrMode: AbsoluteX},
+
+	{Value: 0x24, Name: "BIT", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x2C, Name: "BIT", Length: 3, AddrMode: Absolute},
+
+	{Value: 0x10, Name: "BPL", Length: 2, AddrMode: None}, // all the branch instructions have special cased
+	{Value: 0x30, Name: "BMI", Length: 2, AddrMode: None}, // printing
+	{Value: 0x50, Name: "BVC", Length: 2, AddrMode: None},
+	{Value: 0x70, Name: "BVS", Length: 2, AddrMode: None},
+	{Value: 0x90, Name: "BCC", Length: 2, AddrMode: None},
+	{Value: 0xB0, Name: "BCS", Length: 2, AddrMode: None},
+	{Value: 0xD0, Name: "BNE", Length: 2, AddrMode: None},
+	{Value: 0xF0, Name: "BEQ", Length: 2, AddrMode: None},
+
+	{Value: 0x00, Name: "BRK", Length: 1, AddrMode: None},
+
+	{Value: 0xC9, Name: "CMP", Length: 2, AddrMode: Immediate},
+	{Value: 0xC5, Name: "CMP", Length: 2, AddrMode: ZeroPage},
+	{Value: 0xD5, Name: "CMP", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0xCD, Name: "CMP", Length: 3, AddrMode: Absolute},
+	{Value: 0xDD, Name: "CMP", Length: 3, AddrMode: AbsoluteX},
+	{Value: 0xD9, Name: "CMP", Length: 3, AddrMode: AbsoluteY},
+	{Value: 0xC1, Name: "CMP", Length: 2, AddrMode: IndirectX},
+	{Value: 0xD1, Name: "CMP", Length: 2, AddrMode: IndirectY},
+
+	{Value: 0xE0, Name: "CPX", Length: 2, AddrMode: Immediate},
+	{Value: 0xE4, Name: "CPX", Length: 2, AddrMode: ZeroPage},
+	{Value: 0xEC, Name: "CPX", Length: 3, AddrMode: Absolute},
+
+	{Value: 0xC0, Name: "CPY", Length: 2, AddrMode: Immediate},
+	{Value: 0xC4, Name: "CPY", Length: 2, AddrMode: ZeroPage},
+	{Value: 0xCC, Name: "CPY", Length: 3, AddrMode: Absolute},
+
+	{Value: 0xC6, Name: "DEC", Length: 2, AddrMode: ZeroPage},
+	{Value: 0xD6, Name: "DEC", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0xCE, Name: "DEC", Length: 3, AddrMode: Absolute},
+	{Value: 0xDE, Name: "DEC", Length: 3, AddrMode: AbsoluteX},
+
+	{Value: 0x49, Name: "EOR", Length: 2, AddrMode: Immediate},
+	{Value: 0x45, Name: "EOR", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x55, Name: "EOR", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0x4D, Name: "EOR", Length: 3, AddrMode: Absolute},
+	{Value: 0x5D, Name: "EOR", Length: 3, AddrMode: AbsoluteX},
+	{Value: 0x59, Name: "EOR", Length: 3, AddrMode: AbsoluteY},
+	{Value: 0x41, Name: "EOR", Length: 2, AddrMode: IndirectX},
+	{Value: 0x51, Name: "EOR", Length: 2, AddrMode: IndirectY},
+
+	{Value: 0x18, Name: "CLC", Length: 1, AddrMode: None},
+	{Value: 0x38, Name: "SEC", Length: 1, AddrMode: None},
+	{Value: 0x58, Name: "CLI", Length: 1, AddrMode: None},
+	{Value: 0x78, Name: "SEI", Length: 1, AddrMode: None},
+	{Value: 0xB8, Name: "CLV", Length: 1, AddrMode: None},
+	{Value: 0xD8, Name: "CLD", Length: 1, AddrMode: None},
+	{Value: 0xF8, Name: "SED", Length: 1, AddrMode: None},
+
+	{Value: 0xE6, Name: "INC", Length: 2, AddrMode: ZeroPage},
+	{Value: 0xF6, Name: "INC", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0xEE, Name: "INC", Length: 3, AddrMode: Absolute},
+	{Value: 0xFE, Name: "INC", Length: 3, AddrMode: AbsoluteX},
+
+	{Value: OpJMPAbsolute, Name: "JMP", Length: 3, AddrMode: Absolute}, // special cased when printing
+	{Value: OpJMPIndirect, Name: "JMP", Length: 3, AddrMode: Indirect},
+
+	{Value: OpJSRAbsolute, Name: "JSR", Length: 3, AddrMode: Absolute}, // special cased when printing
+
+	{Value: 0xA9, Name: "LDA", Length: 2, AddrMode: Immediate},
+	{Value: 0xA5, Name: "LDA", Length: 2, AddrMode: ZeroPage},
+	{Value: 0xB5, Name: "LDA", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0xAD, Name: "LDA", Length: 3, AddrMode: Absolute},
+	{Value: 0xBD, Name: "LDA", Length: 3, AddrMode: AbsoluteX},
+	{Value: 0xB9, Name: "LDA", Length: 3, AddrMode: AbsoluteY},
+	{Value: 0xA1, Name: "LDA", Length: 2, AddrMode: IndirectX},
+	{Value: 0xB1, Name: "LDA", Length: 2, AddrMode: IndirectY},
+
+	{Value: 0xA2, Name: "LDX", Length: 2, AddrMode: Immediate},
+	{Value: 0xA6, Name: "LDX", Length: 2, AddrMode: ZeroPage},
+	{Value: 0xB6, Name: "LDX", Length: 2, AddrMode: ZeroPageY},
+	{Value: 0xAE, Name: "LDX", Length: 3, AddrMode: Absolute},
+	{Value: 0xBE, Name: "LDX", Length: 3, AddrMode: AbsoluteY},
+
+	{Value: 0xA0, Name: "LDY", Length: 2, AddrMode: Immediate},
+	{Value: 0xA4, Name: "LDY", Length: 2, AddrMode: ZeroPage},
+	{Value: 0xB4, Name: "LDY", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0xAC, Name: "LDY", Length: 3, AddrMode: Absolute},
+	{Value: 0xBC, Name: "LDY", Length: 3, AddrMode: AbsoluteX},
+
+	{Value: 0x4A, Name: "LSR", Length: 1, AddrMode: Accumulator},
+	{Value: 0x46, Name: "LSR", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x56, Name: "LSR", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0x4E, Name: "LSR", Length: 3, AddrMode: Absolute},
+	{Value: 0x5E, Name: "LSR", Length: 3, AddrMode: AbsoluteX},
+
+	{Value: 0xEA, Name: "NOP", Length: 1, AddrMode: None},
+
+	{Value: 0x09, Name: "ORA", Length: 2, AddrMode: Immediate},
+	{Value: 0x05, Name: "ORA", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x15, Name: "ORA", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0x0D, Name: "ORA", Length: 3, AddrMode: Absolute},
+	{Value: 0x1D, Name: "ORA", Length: 3, AddrMode: AbsoluteX},
+	{Value: 0x19, Name: "ORA", Length: 3, AddrMode: AbsoluteY},
+	{Value: 0x01, Name: "ORA", Length: 2, AddrMode: IndirectX},
+	{Value: 0x11, Name: "ORA", Length: 2, AddrMode: IndirectY},
+
+	{Value: 0xAA, Name: "TAX", Length: 1, AddrMode: None},
+	{Value: 0x8A, Name: "TXA", Length: 1, AddrMode: None},
+	{Value: 0xCA, Name: "DEX", Length: 1, AddrMode: None},
+	{Value: 0xE8, Name: "INX", Length: 1, AddrMode: None},
+	{Value: 0xA8, Name: "TAY", Length: 1, AddrMode: None},
+	{Value: 0x98, Name: "TYA", Length: 1, AddrMode: None},
+	{Value: 0x88, Name: "DEY", Length: 1, AddrMode: None},
+	{Value: 0xC8, Name: "INY", Length: 1, AddrMode: None},
+
+	{Value: 0x2A, Name: "ROL", Length: 1, AddrMode: Accumulator},
+	{Value: 0x26, Name: "ROL", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x36, Name: "ROL", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0x2E, Name: "ROL", Length: 3, AddrMode: Absolute},
+	{Value: 0x3E, Name: "ROL", Length: 3, AddrMode: AbsoluteX},
+
+	{Value: 0x6A, Name: "ROR", Length: 1, AddrMode: Accumulator},
+	{Value: 0x66, Name: "ROR", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x76, Name: "ROR", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0x6E, Name: "ROR", Length: 3, AddrMode: Absolute},
+	{Value: 0x7E, Name: "ROR", Length: 3, AddrMode: AbsoluteX},
+
+	{Value: 0x40, Name: "RTI", Length: 1, AddrMode: None},
+
+	{Value: 0x60, Name: "RTS", Length: 1, AddrMode: None},
+
+	{Value: 0xE9, Name: "SBC", Length: 2, AddrMode: Immediate},
+	{Value: 0xE5, Name: "SBC", Length: 2, AddrMode: ZeroPage},
+	{Value: 0xF5, Name: "SBC", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0xED, Name: "SBC", Length: 3, AddrMode: Absolute},
+	{Value: 0xFD, Name: "SBC", Length: 3, AddrMode: AbsoluteX},
+	{Value: 0xF9, Name: "SBC", Length: 3, AddrMode: AbsoluteY},
+	{Value: 0xE1, Name: "SBC", Length: 2, AddrMode: IndirectX},
+	{Value: 0xF1, Name: "SBC", Length: 2, AddrMode: IndirectY},
+
+	{Value: 0x85, Name: "STA", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x95, Name: "STA", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0x8D, Name: "STA", Length: 3, AddrMode: Absolute},
+	{Value: 0x9D, Name: "STA", Length: 3, AddrMode: AbsoluteX},
+	{Value: 0x99, Name: "STA", Length: 3, AddrMode: AbsoluteY},
+	{Value: 0x81, Name: "STA", Length: 2, AddrMode: IndirectX},
+	{Value: 0x91, Name: "STA", Length: 2, AddrMode: IndirectY},
+
+	{Value: 0x9A, Name: "TXS", Length: 1, AddrMode: None},
+	{Value: 0xBA, Name: "TSX", Length: 1, AddrMode: None},
+	{Value: 0x48, Name: "PHA", Length: 1, AddrMode: None},
+	{Value: 0x68, Name: "PLA", Length: 1, AddrMode: None},
+	{Value: 0x08, Name: "PHP", Length: 1, AddrMode: None},
+	{Value: 0x28, Name: "PLP", Length: 1, AddrMode: None},
+
+	{Value: 0x86, Name: "STX", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x96, Name: "STX", Length: 2, AddrMode: ZeroPageY},
+	{Value: 0x8E, Name: "STX", Length: 3, AddrMode: Absolute},
+
+	{Value: 0x84, Name: "STY", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x94, Name: "STY", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0x8C, Name: "STY", Length: 3, AddrMode: Absolute},
+}
+
+// opCodes65C02Extra defines the instructions and addressing modes added by
+// the CMOS 65C02 over the NMOS 6502, common to every CMOS variant.
+var opCodes65C02Extra = []Opcode{
+	{Value: 0x72, Name: "ADC", Length: 2, AddrMode: IndirectZP},
+	{Value: 0x32, Name: "AND", Length: 2, AddrMode: IndirectZP},
+	{Value: 0x12, Name: "ORA", Length: 2, AddrMode: IndirectZP},
+	{Value: 0x52, Name: "EOR", Length: 2, AddrMode: IndirectZP},
+	{Value: 0xD2, Name: "CMP", Length: 2, AddrMode: IndirectZP},
+	{Value: 0xF2, Name: "SBC", Length: 2, AddrMode: IndirectZP},
+	{Value: 0x92, Name: "STA", Length: 2, AddrMode: IndirectZP},
+	{Value: 0xB2, Name: "LDA", Length: 2, AddrMode: IndirectZP},
+
+	{Value: 0x80, Name: "BRA", Length: 2, AddrMode: None},
+
+	{Value: 0x89, Name: "BIT", Length: 2, AddrMode: Immediate},
+	{Value: 0x34, Name: "BIT", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0x3C, Name: "BIT", Length: 3, AddrMode: AbsoluteX},
+
+	{Value: 0x64, Name: "STZ", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x74, Name: "STZ", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0x9C, Name: "STZ", Length: 3, AddrMode: Absolute},
+	{Value: 0x9E, Name: "STZ", Length: 3, AddrMode: AbsoluteX},
+
+	{Value: 0x1A, Name: "INC", Length: 1, AddrMode: Accumulator},
+	{Value: 0x3A, Name: "DEC", Length: 1, AddrMode: Accumulator},
+
+	{Value: 0x04, Name: "TSB", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x0C, Name: "TSB", Length: 3, AddrMode: Absolute},
+	{Value: 0x14, Name: "TRB", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x1C, Name: "TRB", Length: 3, AddrMode: Absolute},
+
+	{Value: 0xDA, Name: "PHX", Length: 1, AddrMode: None},
+	{Value: 0xFA, Name: "PLX", Length: 1, AddrMode: None},
+	{Value: 0x5A, Name: "PHY", Length: 1, AddrMode: None},
+	{Value: 0x7A, Name: "PLY", Length: 1, AddrMode: None},
+
+	{Value: 0x7C, Name: "JMP", Length: 3, AddrMode: IndirectAbsoluteX}, // JMP (&1234,X)
+}
+
+// opCodes65C02NOPs defines the documented no-op encodings the CMOS 65C02
+// puts in the opcode slots that are illegal on the NMOS 6502. Unlike the
+// NMOS illegal opcodes (see opCodesIllegalStable), these are fully
+// documented, stable across 65C02 manufacturers, and read (but discard) any
+// operand bytes their addressing mode implies.
+var opCodes65C02NOPs = []Opcode{
+	{Value: 0x03, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0x13, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0x23, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0x33, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0x43, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0x53, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0x63, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0x73, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0x83, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0x93, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0xA3, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0xB3, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0xC3, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0xD3, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0xE3, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0xF3, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0x0B, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0x1B, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0x2B, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0x3B, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0x4B, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0x5B, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0x6B, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0x7B, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0x8B, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0x9B, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0xAB, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0xBB, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0xCB, Name: "NOP", Length: 1, AddrMode: None}, // WAI on CPUWDC65C02
+	{Value: 0xDB, Name: "NOP", Length: 1, AddrMode: None}, // STP on CPUWDC65C02
+	{Value: 0xEB, Name: "NOP", Length: 1, AddrMode: None},
+	{Value: 0xFB, Name: "NOP", Length: 1, AddrMode: None},
+
+	{Value: 0x02, Name: "NOP", Length: 2, AddrMode: Immediate},
+	{Value: 0x22, Name: "NOP", Length: 2, AddrMode: Immediate},
+	{Value: 0x42, Name: "NOP", Length: 2, AddrMode: Immediate},
+	{Value: 0x62, Name: "NOP", Length: 2, AddrMode: Immediate},
+	{Value: 0x82, Name: "NOP", Length: 2, AddrMode: Immediate},
+	{Value: 0xC2, Name: "NOP", Length: 2, AddrMode: Immediate},
+	{Value: 0xE2, Name: "NOP", Length: 2, AddrMode: Immediate},
+
+	{Value: 0x44, Name: "NOP", Length: 2, AddrMode: ZeroPage},
+
+	{Value: 0x54, Name: "NOP", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0xD4, Name: "NOP", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0xF4, Name: "NOP", Length: 2, AddrMode: ZeroPageX},
+
+	{Value: 0x5C, Name: "NOP", Length: 3, AddrMode: Absolute},
+
+	{Value: 0xDC, Name: "NOP", Length: 3, AddrMode: AbsoluteX},
+	{Value: 0xFC, Name: "NOP", Length: 3, AddrMode: AbsoluteX},
+}
+
+// opCodesRockwellExtra defines the bit-manipulation instructions added by
+// the Rockwell R65C02, as shipped in the BBC Master 128.
+var opCodesRockwellExtra = []Opcode{
+	{Value: 0x0F, Name: "BBR0", Length: 3, AddrMode: None},
+	{Value: 0x1F, Name: "BBR1", Length: 3, AddrMode: None},
+	{Value: 0x2F, Name: "BBR2", Length: 3, AddrMode: None},
+	{Value: 0x3F, Name: "BBR3", Length: 3, AddrMode: None},
+	{Value: 0x4F, Name: "BBR4", Length: 3, AddrMode: None},
+	{Value: 0x5F, Name: "BBR5", Length: 3, AddrMode: None},
+	{Value: 0x6F, Name: "BBR6", Length: 3, AddrMode: None},
+	{Value: 0x7F, Name: "BBR7", Length: 3, AddrMode: None},
+	{Value: 0x8F, Name: "BBS0", Length: 3, AddrMode: None},
+	{Value: 0x9F, Name: "BBS1", Length: 3, AddrMode: None},
+	{Value: 0xAF, Name: "BBS2", Length: 3, AddrMode: None},
+	{Value: 0xBF, Name: "BBS3", Length: 3, AddrMode: None},
+	{Value: 0xCF, Name: "BBS4", Length: 3, AddrMode: None},
+	{Value: 0xDF, Name: "BBS5", Length: 3, AddrMode: None},
+	{Value: 0xEF, Name: "BBS6", Length: 3, AddrMode: None},
+	{Value: 0xFF, Name: "BBS7", Length: 3, AddrMode: None},
+
+	{Value: 0x07, Name: "RMB0", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x17, Name: "RMB1", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x27, Name: "RMB2", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x37, Name: "RMB3", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x47, Name: "RMB4", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x57, Name: "RMB5", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x67, Name: "RMB6", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x77, Name: "RMB7", Length: 2, AddrMode: ZeroPage},
+
+	{Value: 0x87, Name: "SMB0", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x97, Name: "SMB1", Length: 2, AddrMode: ZeroPage},
+	{Value: 0xA7, Name: "SMB2", Length: 2, AddrMode: ZeroPage},
+	{Value: 0xB7, Name: "SMB3", Length: 2, AddrMode: ZeroPage},
+	{Value: 0xC7, Name: "SMB4", Length: 2, AddrMode: ZeroPage},
+	{Value: 0xD7, Name: "SMB5", Length: 2, AddrMode: ZeroPage},
+	{Value: 0xE7, Name: "SMB6", Length: 2, AddrMode: ZeroPage},
+	{Value: 0xF7, Name: "SMB7", Length: 2, AddrMode: ZeroPage},
+}
+
+// opCodesWDCExtra defines the instructions added by WDC's 65C02, on top of
+// the generic CMOS 65C02 additions.
+var opCodesWDCExtra = []Opcode{
+	{Value: 0xCB, Name: "WAI", Length: 1, AddrMode: None},
+	{Value: 0xDB, Name: "STP", Length: 1, AddrMode: None},
+}
+
+// withoutOpcodes returns a copy of ops with any entry whose Value also
+// appears in overrides removed, so overrides can be appended on top without
+// leaving two literal entries for the same byte value in the result (which
+// OpCodeSetWithIllegals would otherwise resolve by silent append-order
+// shadowing rather than a real, table-visible override).
+func withoutOpcodes(ops []Opcode, overrides []Opcode) []Opcode {
+	shadowed := make(map[byte]bool, len(overrides))
+	for _, o := range overrides {
+		shadowed[o.Value] = true
 	}
+	out := make([]Opcode, 0, len(ops))
+	for _, op := range ops {
+		if !shadowed[op.Value] {
+			out = append(out, op)
+		}
+	}
+	return out
+}
 
-	// OpCodesMap maps from opcode byte value to Opcode. Initialized by init()
+// IllegalsMode selects how much of the NMOS 6502's undocumented opcode space
+// a Disassembler recognises as instructions, rather than treating each such
+// byte as a lone data byte. Disassemble still writes a recognised
+// undocumented instruction as a data directive of all its bytes, naming its
+// mnemonic after "UD" in the trailing comment. The unused opcode values only exist as holes in the NMOS
+// decode PLA; the 65C02 and its derivatives repurpose them for documented
+// instructions, so IllegalsMode only affects CPUNMOS.
+type IllegalsMode int
+
+// Illegals modes
+const (
+	// IllegalsNone decodes only documented NMOS instructions (plus ANC,
+	// which bbcdisasm has always recognised). This is the default, and
+	// matches bbcdisasm's historical behaviour.
+	IllegalsNone IllegalsMode = iota
+	// IllegalsStable additionally decodes the illegal opcodes whose
+	// behaviour is consistent across 6502 chip revisions: LAX, SAX, DCP,
+	// ISC, RLA, RRA, ARR, ASR, SBX, and the KIL/JAM halt instructions.
+	IllegalsStable
+	// IllegalsAll additionally decodes the illegal opcodes whose behaviour
+	// varies by chip revision and temperature (Opcode.Unstable is true):
+	// SHA, SHX, SHY, TAS and LAS.
+	IllegalsAll
+)
+
+// opCodesIllegalStable defines the NMOS illegal opcodes with well-defined,
+// chip-revision-independent behaviour.
+var opCodesIllegalStable = []Opcode{
+	{Value: 0xA7, Name: "LAX", Length: 2, AddrMode: ZeroPage},
+	{Value: 0xB7, Name: "LAX", Length: 2, AddrMode: ZeroPageY},
+	{Value: 0xAF, Name: "LAX", Length: 3, AddrMode: Absolute},
+	{Value: 0xBF, Name: "LAX", Length: 3, AddrMode: AbsoluteY},
+	{Value: 0xA3, Name: "LAX", Length: 2, AddrMode: IndirectX},
+	{Value: 0xB3, Name: "LAX", Length: 2, AddrMode: IndirectY},
+
+	{Value: 0x87, Name: "SAX", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x97, Name: "SAX", Length: 2, AddrMode: ZeroPageY},
+	{Value: 0x8F, Name: "SAX", Length: 3, AddrMode: Absolute},
+	{Value: 0x83, Name: "SAX", Length: 2, AddrMode: IndirectX},
+
+	{Value: 0xC7, Name: "DCP", Length: 2, AddrMode: ZeroPage},
+	{Value: 0xD7, Name: "DCP", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0xCF, Name: "DCP", Length: 3, AddrMode: Absolute},
+	{Value: 0xDF, Name: "DCP", Length: 3, AddrMode: AbsoluteX},
+	{Value: 0xDB, Name: "DCP", Length: 3, AddrMode: AbsoluteY},
+	{Value: 0xC3, Name: "DCP", Length: 2, AddrMode: IndirectX},
+	{Value: 0xD3, Name: "DCP", Length: 2, AddrMode: IndirectY},
+
+	{Value: 0xE7, Name: "ISC", Length: 2, AddrMode: ZeroPage},
+	{Value: 0xF7, Name: "ISC", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0xEF, Name: "ISC", Length: 3, AddrMode: Absolute},
+	{Value: 0xFF, Name: "ISC", Length: 3, AddrMode: AbsoluteX},
+	{Value: 0xFB, Name: "ISC", Length: 3, AddrMode: AbsoluteY},
+	{Value: 0xE3, Name: "ISC", Length: 2, AddrMode: IndirectX},
+	{Value: 0xF3, Name: "ISC", Length: 2, AddrMode: IndirectY},
+
+	{Value: 0x27, Name: "RLA", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x37, Name: "RLA", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0x2F, Name: "RLA", Length: 3, AddrMode: Absolute},
+	{Value: 0x3F, Name: "RLA", Length: 3, AddrMode: AbsoluteX},
+	{Value: 0x3B, Name: "RLA", Length: 3, AddrMode: AbsoluteY},
+	{Value: 0x23, Name: "RLA", Length: 2, AddrMode: IndirectX},
+	{Value: 0x33, Name: "RLA", Length: 2, AddrMode: IndirectY},
+
+	{Value: 0x67, Name: "RRA", Length: 2, AddrMode: ZeroPage},
+	{Value: 0x77, Name: "RRA", Length: 2, AddrMode: ZeroPageX},
+	{Value: 0x6F, Name: "RRA", Length: 3, AddrMode: Absolute},
+	{Value: 0x7F, Name: "RRA", Length: 3, AddrMode: AbsoluteX},
+	{Value: 0x7B, Name: "RRA", Length: 3, AddrMode: AbsoluteY},
+	{Value: 0x63, Name: "RRA", Length: 2, AddrMode: IndirectX},
+	{Value: 0x73, Name: "RRA", Length: 2, AddrMode: IndirectY},
+
+	{Value: 0x6B, Name: "ARR", Length: 2, AddrMode: Immediate},
+	{Value: 0x4B, Name: "ASR", Length: 2, AddrMode: Immediate},
+	{Value: 0xCB, Name: "SBX", Length: 2, AddrMode: Immediate},
+
+	// JAM (aka KIL/HLT) locks up the CPU until reset; there is no operand
+	// and nothing after it can execute, but it still occupies a byte of
+	// program space so it needs a decode rather than falling through to
+	// .byte.
+	{Value: 0x02, Name: "JAM", Length: 1, AddrMode: None},
+	{Value: 0x12, Name: "JAM", Length: 1, AddrMode: None},
+	{Value: 0x22, Name: "JAM", Length: 1, AddrMode: None},
+	{Value: 0x32, Name: "JAM", Length: 1, AddrMode: None},
+	{Value: 0x42, Name: "JAM", Length: 1, AddrMode: None},
+	{Value: 0x52, Name: "JAM", Length: 1, AddrMode: None},
+	{Value: 0x62, Name: "JAM", Length: 1, AddrMode: None},
+	{Value: 0x72, Name: "JAM", Length: 1, AddrMode: None},
+	{Value: 0x92, Name: "JAM", Length: 1, AddrMode: None},
+	{Value: 0xB2, Name: "JAM", Length: 1, AddrMode: None},
+	{Value: 0xD2, Name: "JAM", Length: 1, AddrMode: None},
+	{Value: 0xF2, Name: "JAM", Length: 1, AddrMode: None},
+}
+
+// opCodesIllegalUnstable defines the NMOS illegal opcodes whose behaviour is
+// sensitive to chip revision, temperature and bus timing; real-world
+// programs avoid relying on them, so they're only decoded under
+// IllegalsAll.
+var opCodesIllegalUnstable = []Opcode{
+	{Value: 0x9F, Name: "SHA", Length: 3, AddrMode: AbsoluteY, Unstable: true},
+	{Value: 0x93, Name: "SHA", Length: 2, AddrMode: IndirectY, Unstable: true},
+	{Value: 0x9E, Name: "SHX", Length: 3, AddrMode: AbsoluteY, Unstable: true},
+	{Value: 0x9C, Name: "SHY", Length: 3, AddrMode: AbsoluteX, Unstable: true},
+	{Value: 0x9B, Name: "TAS", Length: 3, AddrMode: AbsoluteY, Unstable: true},
+	{Value: 0xBB, Name: "LAS", Length: 3, AddrMode: AbsoluteY, Unstable: true},
+}
+
+var (
+	// OpCodesNMOS defines the documented instructions of the NMOS 6502 CPU,
+	// as shipped in the BBC Model B and Electron. It also includes a couple
+	// of undocumented instructions.
+	// Most opcodes from http://www.6502.org/tutorials/6502opcodes.html
+	// ANC from https://github.com/mattgodbolt/jsbeeb/blob/master/6502.opcodes.js
+	OpCodesNMOS = append(append([]Opcode{}, opCodesCommon...), Opcode{Value: 0x0B, Name: "ANC", Length: 2, AddrMode: Immediate}, Opcode{Value: 0x2B, Name: "ANC", Length: 2, AddrMode: Immediate})
+
+	// OpCodes65C02 defines the documented instructions of the generic CMOS
+	// 65C02, used by machines such as the Apple IIc, including the NOPs that
+	// fill the slots left illegal by the NMOS 6502.
+	OpCodes65C02 = append(append(append([]Opcode{}, opCodesCommon...), opCodes65C02Extra...), opCodes65C02NOPs...)
+
+	// OpCodesR65C02 defines the documented instructions of the Rockwell
+	// R65C02, as shipped in the BBC Master 128, which adds the BBR/BBS/RMB/SMB
+	// bit-manipulation instructions on top of the generic 65C02 (in the x7
+	// and xF opcode slots, which the generic 65C02 table leaves unassigned).
+	OpCodesR65C02 = append(append(append(append([]Opcode{}, opCodesCommon...), opCodes65C02Extra...), opCodes65C02NOPs...), opCodesRockwellExtra...)
+
+	// OpCodesWDC65C02 defines the documented instructions of the WDC 65C02,
+	// which adds WAI/STP on top of the generic 65C02, replacing the generic
+	// NOP at the same two opcodes rather than merely shadowing it.
+	OpCodesWDC65C02 = append(append(append([]Opcode{}, opCodesCommon...), opCodes65C02Extra...), append(withoutOpcodes(opCodes65C02NOPs, opCodesWDCExtra), opCodesWDCExtra...)...)
+
+	// OpCodes is retained as an alias of OpCodesNMOS for backwards
+	// compatibility; new code should prefer OpCodeSet(CPUNMOS).
+	OpCodes = OpCodesNMOS
+
+	// OpCodesMap maps from opcode byte value to Opcode for the NMOS 6502.
+	// Initialized by init(). Equivalent to OpCodeSet(CPUNMOS).
 	OpCodesMap map[byte]Opcode
 
-	// UndocumentedInstructions is not exhaustive and only tracks the opcodes
-	// that are included in OpCodesMap.
-	UndocumentedInstructions = []string{"ANC", "SRE", "SLO"}
+	// UndocumentedInstructions lists every undocumented NMOS mnemonic
+	// bbcdisasm can decode, across all IllegalsMode settings, not just
+	// those in OpCodesNMOS's default (IllegalsNone) table.
+	UndocumentedInstructions = []string{
+		"ANC", "LAX", "SAX", "DCP", "ISC", "RLA", "RRA", "ARR", "ASR", "SBX", "JAM",
+		"SHA", "SHX", "SHY", "TAS", "LAS",
+	}
 
 	branchInstructions = []string{"BPL", "BMI", "BVC", "BRA", "BVS", "BCC", "BCS", "BNE", "BEQ", "BBR0", "BBR1", "BBR2", "BBR3", "BBR4", "BBR5", "BBR6", "BBR7", "BBS0", "BBS1", "BBS2", "BBS3", "BBS4", "BBS5", "BBS6", "BBS7"}
 
@@ -377,9 +668,51 @@ const (
 )
 
 func init() {
-	OpCodesMap = make(map[byte]Opcode)
-	for _, op := range OpCodes {
-		OpCodesMap[op.Value] = op
+	OpCodesMap = OpCodeSet(CPUNMOS)
+}
+
+// OpCodeSet returns the map[byte]Opcode appropriate for the given CPUVariant,
+// so that a disassembler can route opcode lookups through the table that
+// matches the machine the code was written for. It decodes no illegal
+// opcodes; see OpCodeSetWithIllegals to decode copy-protection tape loaders
+// and demos that rely on them.
+func OpCodeSet(v CPUVariant) map[byte]Opcode {
+	return OpCodeSetWithIllegals(v, IllegalsNone)
+}
+
+// OpCodeSetWithIllegals is OpCodeSet, extended with the NMOS illegal opcodes
+// selected by illegals. illegals is ignored for every variant but CPUNMOS:
+// the 65C02 and its derivatives repurpose the NMOS's illegal opcode holes
+// for documented instructions, so there's nothing left to add.
+func OpCodeSetWithIllegals(v CPUVariant, illegals IllegalsMode) map[byte]Opcode {
+	var ops []Opcode
+	switch v {
+	case CPU65C02:
+		ops = OpCodes65C02
+	case CPUR65C02:
+		ops = OpCodesR65C02
+	case CPUWDC65C02:
+		ops = OpCodesWDC65C02
+	default:
+		ops = append(append([]Opcode{}, OpCodesNMOS...), illegalOpCodes(illegals)...)
+	}
+
+	m := make(map[byte]Opcode, len(ops))
+	for _, op := range ops {
+		m[op.Value] = op
+	}
+	return m
+}
+
+// illegalOpCodes returns the NMOS illegal opcodes selected by illegals.
+func illegalOpCodes(illegals IllegalsMode) []Opcode {
+	switch illegals {
+	case IllegalsStable:
+		return opCodesIllegalStable
+	case IllegalsAll:
+		return append(append([]Opcode{}, opCodesIllegalStable...), opCodesIllegalUnstable...)
+	default:
+		return nil
 	}
 }
 
@@ -399,41 +732,23 @@ func (o *Opcode) branchOrJump() branchType {
 	return btNeither
 }
 
-func genAbsoluteOsCall(bytes []byte, branchTargets map[uint]int) string {
-	addr := (uint(bytes[2]) << 8) + uint(bytes[1])
-
-	// Check if it is a well known OS address
-	if osCall, ok := addressToOsCallName[addr]; ok {
-		return osCall
-	}
-
-	// Check if it is a known branch target
-	if tgtIdx, ok := branchTargets[addr]; ok {
-		return fmt.Sprintf(labelFormatString, tgtIdx)
-	}
-
-	return fmt.Sprintf("&%04X", addr)
-}
-
 func genBranch(bytes []byte, cursor, branchAdjust uint, branchTargets map[uint]int, length uint) string {
 	// From http://www.6502.org/tutorials/6502opcodes.html
 	// "When calculating branches a forward branch of 6 skips the following 6
 	// bytes so, effectively the program counter points to the address that is 8
 	// bytes beyond the address of the branch opcode; and a backward branch of
 	// $FA (256-6) goes to an address 4 bytes before the branch instruction."
+	tgt := branchTarget(bytes, cursor, branchAdjust, length)
 	boff := int(bytes[1]) // All branches are 2 bytes long
 	if length == 3 {
 		boff = int(bytes[2])
 	}
-
 	if boff > 127 {
 		boff = boff - 256
 	}
 	// Adjust offset to account for the 2 byte behavior from the comment block
 	// above.
 	boff += int(length)
-
-	tgt := cursor + uint(boff) + branchAdjust
 	// TODO: Explore branch relative offset in the end of line comment
 
 	tgtIdx, ok := branchTargets[tgt]
@@ -0,0 +1,31 @@
+package bbcdisasm
+
+import "testing"
+
+// namedOpCodeTables lists every opcode table that OpCodeSetWithIllegals feeds
+// into a byte->Opcode map, by name. Duplicate Opcode.Value entries within one
+// of these tables silently collapse to whichever entry appends last when the
+// map is built, so a duplicate never produces a compile or runtime error on
+// its own - it just corrupts disassembly for the opcode it displaced.
+var namedOpCodeTables = map[string][]Opcode{
+	"OpCodesNMOS":     OpCodesNMOS,
+	"OpCodes65C02":    OpCodes65C02,
+	"OpCodesR65C02":   OpCodesR65C02,
+	"OpCodesWDC65C02": OpCodesWDC65C02,
+}
+
+// TestOpCodeTablesHaveNoDuplicateValues guards against the class of bug that
+// motivated bbcasm's round-trip test (see TestAssembleRoundTrip): two table
+// entries sharing the same Opcode.Value, so one mnemonic silently shadows
+// another at the byte level.
+func TestOpCodeTablesHaveNoDuplicateValues(t *testing.T) {
+	for name, table := range namedOpCodeTables {
+		seen := make(map[byte]Opcode, len(table))
+		for _, op := range table {
+			if prev, ok := seen[op.Value]; ok {
+				t.Errorf("%s: opcode &%02X used by both %s and %s", name, op.Value, prev.Name, op.Name)
+			}
+			seen[op.Value] = op
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package bbcdisasm
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Diagnostic is a warning produced by AnalyzeRegisters: a place in the
+// program where the decoded instruction stream looks like a well-known
+// 6502/BBC programming mistake, e.g. an ADC with no preceding CLC/SEC to
+// establish the carry.
+type Diagnostic struct {
+	Addr    uint   // Load address (with BranchAdjust already applied) the diagnostic concerns
+	Message string // Human readable description, e.g. "ADC with undefined carry"
+}
+
+// latticeState is the three-point lattice AnalyzeRegisters tracks each
+// register and flag against: lvUnknown (not yet observed; the lattice
+// bottom), lvConst (a known, exact value) and lvAny (could be any value;
+// the lattice top, reached once two branches disagree or the value comes
+// from somewhere this pass doesn't model, e.g. memory).
+type latticeState int
+
+const (
+	lvUnknown latticeState = iota
+	lvConst
+	lvAny
+)
+
+// latticeValue pairs a latticeState with the concrete value, meaningful
+// only when State is lvConst.
+type latticeValue struct {
+	State latticeState
+	Value byte
+}
+
+var anyValue = latticeValue{State: lvAny}
+
+func constValue(v byte) latticeValue { return latticeValue{State: lvConst, Value: v} }
+
+// join merges two observations of the same register/flag reaching a
+// control-flow merge point: equal values (including two lvUnknowns) agree,
+// an lvUnknown side defers to whatever the other side knows, and anything
+// else widens to lvAny.
+func (lv latticeValue) join(other latticeValue) latticeValue {
+	if lv == other {
+		return lv
+	}
+	if lv.State == lvUnknown {
+		return other
+	}
+	if other.State == lvUnknown {
+		return lv
+	}
+	return anyValue
+}
+
+// regState is the abstract machine state AnalyzeRegisters threads through
+// the program: the three general-purpose registers, plus the flags that
+// the pitfalls it looks for depend on.
+type regState struct {
+	A, X, Y       latticeValue
+	C, Z, N, D, I latticeValue
+
+	// aClobberedBy names the OS call most recently reached via a JSR that
+	// invalidated A, for as long as A hasn't been rewritten since ("" if A
+	// wasn't last touched that way). Drives the warning for a read of A
+	// that assumes it survived the call.
+	aClobberedBy string
+}
+
+func (s regState) join(o regState) regState {
+	j := regState{
+		A: s.A.join(o.A), X: s.X.join(o.X), Y: s.Y.join(o.Y),
+		C: s.C.join(o.C), Z: s.Z.join(o.Z), N: s.N.join(o.N),
+		D: s.D.join(o.D), I: s.I.join(o.I),
+	}
+	if s.aClobberedBy == o.aClobberedBy {
+		j.aClobberedBy = s.aClobberedBy
+	}
+	return j
+}
+
+// zn derives the Z and N flags that loading v into a register would set;
+// anyValue for both if v isn't known exactly.
+func zn(v latticeValue) (z, n latticeValue) {
+	if v.State != lvConst {
+		return anyValue, anyValue
+	}
+	var zb, nb byte
+	if v.Value == 0 {
+		zb = 1
+	}
+	if v.Value&0x80 != 0 {
+		nb = 1
+	}
+	return constValue(zb), constValue(nb)
+}
+
+// loadValue returns the exact value an Immediate-mode load sets its
+// register to, or anyValue for every other addressing mode (AnalyzeRegisters
+// doesn't model memory contents).
+func loadValue(op Opcode, instruction []byte) latticeValue {
+	if op.AddrMode == Immediate {
+		return constValue(instruction[1])
+	}
+	return anyValue
+}
+
+// mnemonicsReadingA are instructions (other than the shift/rotate group,
+// handled separately since they only read A in Accumulator mode) whose
+// operand is the accumulator.
+var mnemonicsReadingA = map[string]bool{
+	"STA": true, "ADC": true, "SBC": true, "CMP": true,
+	"AND": true, "ORA": true, "EOR": true, "PHA": true, "BIT": true,
+	"TAX": true, "TAY": true,
+}
+
+var shiftRotateMnemonics = map[string]bool{"ASL": true, "LSR": true, "ROL": true, "ROR": true}
+
+// AnalyzeRegisters runs an optional, best-effort abstract interpretation of
+// A/X/Y and the C/Z/N/D/I flags over the program's code bytes (the same
+// bytes d.walk(vtCode, ...) visits for findBranchTargets), looking for a
+// handful of well-known Beeb programming mistakes:
+//
+//   - ADC or SBC with the carry in an unknown state (missing CLC/SEC).
+//   - A read of A that assumes it survived a JSR to an OS call that
+//     clobbers it (driven off addressToOsCallName via Symbols.Resolve).
+//   - A branch whose taken target lands mid-instruction or in data rather
+//     than on a decoded instruction boundary.
+//
+// It is forward and flow-sensitive along fall-through and taken branches,
+// joining state at merge points, but makes only one pass per address: a
+// loop's back edge is seen after the loop header has already been visited,
+// so the state it carries is never joined into the header and there is no
+// iteration to a fixpoint. The header's state reflects only the paths that
+// reach it from earlier addresses.
+func (d *Disassembler) AnalyzeRegisters() []Diagnostic {
+	instrStart := make(map[uint]bool)  // every address walk actually decoded as an instruction
+	pending := make(map[uint]regState) // state forked onto a label by an earlier branch, joined in once reached
+	type branchCheck struct{ addr, target uint }
+	var checks []branchCheck
+	var diagnostics []Diagnostic
+
+	var cur regState
+
+	d.walk(vtCode, func(cursor uint, _ int, b byte, op Opcode, opOk bool) int {
+		if !opOk {
+			return 1
+		}
+
+		addr := cursor + d.BranchAdjust
+		instrStart[addr] = true
+		instruction := d.Program[cursor : cursor+op.Length]
+
+		if joined, ok := pending[addr]; ok {
+			cur = cur.join(joined)
+			delete(pending, addr)
+		}
+
+		switch op.branchOrJump() {
+		case btBranch:
+			tgt := branchTarget(instruction, cursor, d.BranchAdjust, op.Length)
+			checks = append(checks, branchCheck{addr, tgt})
+			pending[tgt] = pending[tgt].join(cur)
+			return len(instruction)
+		case btJump:
+			if b == OpJMPIndirect || b == OpJMPIndirectAbsoluteX {
+				cur = regState{}
+				return len(instruction)
+			}
+
+			tgt := (uint(instruction[2]) << 8) + uint(instruction[1])
+			checks = append(checks, branchCheck{addr, tgt})
+			if op.Name == "JSR" {
+				name, _ := d.Symbols.Resolve(tgt, SymOSCall)
+				cur.A, cur.X, cur.Y = anyValue, anyValue, anyValue
+				cur.C, cur.Z, cur.N = anyValue, anyValue, anyValue
+				cur.aClobberedBy = name
+			} else {
+				// Unconditional jump elsewhere: nothing reliably falls through.
+				cur = regState{}
+			}
+			return len(instruction)
+		}
+
+		d.stepRegState(&cur, op, instruction, addr, &diagnostics)
+
+		return len(instruction)
+	})
+
+	// Only targets inside the disassembled range can be checked; a JSR or JMP
+	// out of it (e.g. to an OS call) has no decoded instruction to land on.
+	lo, hi := d.Offset+d.BranchAdjust, d.Offset+d.MaxBytes+d.BranchAdjust
+	for _, c := range checks {
+		if c.target < lo || c.target >= hi {
+			continue
+		}
+		if !instrStart[c.target] {
+			diagnostics = append(diagnostics, Diagnostic{
+				Addr:    c.addr,
+				Message: fmt.Sprintf("branch target &%04X lies mid-instruction or in data", c.target),
+			})
+		}
+	}
+
+	sort.Slice(diagnostics, func(i, j int) bool { return diagnostics[i].Addr < diagnostics[j].Addr })
+	return diagnostics
+}
+
+// stepRegState applies op's effect on cur, appending a Diagnostic to
+// *diagnostics for any of AnalyzeRegisters' pitfalls it recognizes at addr.
+func (d *Disassembler) stepRegState(cur *regState, op Opcode, instruction []byte, addr uint, diagnostics *[]Diagnostic) {
+	if cur.aClobberedBy != "" {
+		readsA := mnemonicsReadingA[op.Name] || (op.AddrMode == Accumulator && shiftRotateMnemonics[op.Name])
+		if readsA {
+			*diagnostics = append(*diagnostics, Diagnostic{
+				Addr:    addr,
+				Message: fmt.Sprintf("reads A after JSR %s, which may have changed it", cur.aClobberedBy),
+			})
+			cur.aClobberedBy = ""
+		}
+	}
+
+	switch op.Name {
+	case "LDA":
+		cur.A = loadValue(op, instruction)
+		cur.Z, cur.N = zn(cur.A)
+		cur.aClobberedBy = ""
+	case "LDX":
+		cur.X = loadValue(op, instruction)
+		cur.Z, cur.N = zn(cur.X)
+	case "LDY":
+		cur.Y = loadValue(op, instruction)
+		cur.Z, cur.N = zn(cur.Y)
+	case "TAX":
+		cur.X = cur.A
+		cur.Z, cur.N = zn(cur.X)
+	case "TAY":
+		cur.Y = cur.A
+		cur.Z, cur.N = zn(cur.Y)
+	case "TXA":
+		cur.A = cur.X
+		cur.Z, cur.N = zn(cur.A)
+		cur.aClobberedBy = ""
+	case "TYA":
+		cur.A = cur.Y
+		cur.Z, cur.N = zn(cur.A)
+		cur.aClobberedBy = ""
+	case "PLA":
+		cur.A, cur.Z, cur.N = anyValue, anyValue, anyValue
+		cur.aClobberedBy = ""
+	case "PLP":
+		cur.C, cur.Z, cur.N, cur.D, cur.I = anyValue, anyValue, anyValue, anyValue, anyValue
+	case "CLC":
+		cur.C = constValue(0)
+	case "SEC":
+		cur.C = constValue(1)
+	case "CLD":
+		cur.D = constValue(0)
+	case "SED":
+		cur.D = constValue(1)
+	case "CLI":
+		cur.I = constValue(0)
+	case "SEI":
+		cur.I = constValue(1)
+	case "ADC":
+		if cur.C.State == lvUnknown {
+			*diagnostics = append(*diagnostics, Diagnostic{Addr: addr, Message: "ADC with undefined carry"})
+		}
+		cur.A, cur.C, cur.Z, cur.N = anyValue, anyValue, anyValue, anyValue
+		cur.aClobberedBy = ""
+	case "SBC":
+		if cur.C.State == lvUnknown {
+			*diagnostics = append(*diagnostics, Diagnostic{Addr: addr, Message: "SBC with undefined carry"})
+		}
+		cur.A, cur.C, cur.Z, cur.N = anyValue, anyValue, anyValue, anyValue
+		cur.aClobberedBy = ""
+	case "AND", "ORA", "EOR":
+		cur.A, cur.Z, cur.N = anyValue, anyValue, anyValue
+		cur.aClobberedBy = ""
+	case "CMP", "CPX", "CPY":
+		cur.C, cur.Z, cur.N = anyValue, anyValue, anyValue
+	case "INX", "DEX":
+		cur.X, cur.Z, cur.N = anyValue, anyValue, anyValue
+	case "INY", "DEY":
+		cur.Y, cur.Z, cur.N = anyValue, anyValue, anyValue
+	case "ASL", "LSR", "ROL", "ROR":
+		cur.C, cur.Z, cur.N = anyValue, anyValue, anyValue
+		if op.AddrMode == Accumulator {
+			cur.A = anyValue
+			cur.aClobberedBy = ""
+		}
+	case "BIT":
+		cur.Z, cur.N = anyValue, anyValue
+	case "INC", "DEC":
+		cur.Z, cur.N = anyValue, anyValue
+	}
+}
@@ -0,0 +1,100 @@
+package bbcdisasm
+
+import (
+	"strings"
+	"testing"
+)
+
+// diagnosticMessages returns the Message of each Diagnostic, for tests that
+// only care whether a particular warning fired.
+func diagnosticMessages(diagnostics []Diagnostic) []string {
+	var msgs []string
+	for _, d := range diagnostics {
+		msgs = append(msgs, d.Message)
+	}
+	return msgs
+}
+
+func containsSubstring(haystack []string, substr string) bool {
+	for _, h := range haystack {
+		if strings.Contains(h, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// TestAnalyzeRegistersADCUndefinedCarry checks the carry-state pitfall: an
+// ADC with no preceding CLC/SEC should warn, but the same ADC preceded by
+// CLC should not.
+func TestAnalyzeRegistersADCUndefinedCarry(t *testing.T) {
+	// ADC #1 with no carry setup.
+	d := NewDisassemblerForVariant([]byte{0x69, 0x01}, CPUNMOS)
+	d.MaxBytes = uint(len(d.Program))
+	diagnostics := d.AnalyzeRegisters()
+	if !containsSubstring(diagnosticMessages(diagnostics), "undefined carry") {
+		t.Errorf("got %v, want a warning about undefined carry", diagnosticMessages(diagnostics))
+	}
+
+	// CLC; ADC #1.
+	d = NewDisassemblerForVariant([]byte{0x18, 0x69, 0x01}, CPUNMOS)
+	d.MaxBytes = uint(len(d.Program))
+	diagnostics = d.AnalyzeRegisters()
+	if containsSubstring(diagnosticMessages(diagnostics), "undefined carry") {
+		t.Errorf("got %v, want no carry warning after CLC", diagnosticMessages(diagnostics))
+	}
+}
+
+// TestAnalyzeRegistersAClobberedByJSR checks that a JSR to a known
+// A-clobbering OS call followed by a read of A (including via TAX/TAY) is
+// flagged, but a read of X/Y that doesn't touch A is not.
+func TestAnalyzeRegistersAClobberedByJSR(t *testing.T) {
+	tests := []struct {
+		name     string
+		program  []byte
+		wantWarn bool
+	}{
+		// JSR OSWRCH (&FFEE); TAX.
+		{"TAX after JSR", []byte{0x20, 0xEE, 0xFF, 0xAA}, true},
+		// JSR OSWRCH (&FFEE); TAY.
+		{"TAY after JSR", []byte{0x20, 0xEE, 0xFF, 0xA8}, true},
+		// JSR OSWRCH (&FFEE); STA &70.
+		{"STA after JSR", []byte{0x20, 0xEE, 0xFF, 0x85, 0x70}, true},
+		// JSR OSWRCH (&FFEE); INX (doesn't read A).
+		{"INX after JSR", []byte{0x20, 0xEE, 0xFF, 0xE8}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewDisassemblerForVariant(tc.program, CPUNMOS)
+			d.MaxBytes = uint(len(tc.program))
+			diagnostics := d.AnalyzeRegisters()
+			got := containsSubstring(diagnosticMessages(diagnostics), "reads A after JSR OSWRCH")
+			if got != tc.wantWarn {
+				t.Errorf("got diagnostics %v, want warning=%v", diagnosticMessages(diagnostics), tc.wantWarn)
+			}
+		})
+	}
+}
+
+// TestAnalyzeRegistersBranchTarget checks the mid-instruction/data branch
+// target pitfall: a BNE whose target lands on a decoded instruction
+// boundary is not flagged, but one that lands inside another instruction's
+// operand is.
+func TestAnalyzeRegistersBranchTarget(t *testing.T) {
+	// BNE +0 (branches to the very next instruction: NOP). Valid target.
+	d := NewDisassemblerForVariant([]byte{0xD0, 0x00, 0xEA}, CPUNMOS)
+	d.MaxBytes = uint(len(d.Program))
+	diagnostics := d.AnalyzeRegisters()
+	if containsSubstring(diagnosticMessages(diagnostics), "mid-instruction or in data") {
+		t.Errorf("got %v, want no warning for a valid branch target", diagnosticMessages(diagnostics))
+	}
+
+	// BNE +1 (branches into LDA #1's operand byte, not its opcode).
+	d = NewDisassemblerForVariant([]byte{0xD0, 0x01, 0xA9, 0x01}, CPUNMOS)
+	d.MaxBytes = uint(len(d.Program))
+	diagnostics = d.AnalyzeRegisters()
+	if !containsSubstring(diagnosticMessages(diagnostics), "mid-instruction or in data") {
+		t.Errorf("got %v, want a warning for a branch into an operand byte", diagnosticMessages(diagnostics))
+	}
+}
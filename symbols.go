@@ -0,0 +1,134 @@
+package bbcdisasm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// SymKind distinguishes the address space a SymbolTable entry resolves in,
+// since the same numeric address can mean different things depending on
+// where it's used, e.g. a zero-page operand vs. an absolute OS call target.
+type SymKind int
+
+// Symbol kinds
+const (
+	SymOSCall   SymKind = iota // Absolute address of a well known OS call entry point
+	SymVector                  // Absolute address of an OS indirection vector
+	SymZeroPage                // Zero page address of a module-specific label
+)
+
+// SymbolTable resolves addresses to identifiers, replacing bbcdisasm's
+// previously hard-coded BBC Micro B maps with a loadable, machine-selectable
+// table. This allows disassembling code for the Master, Electron, or
+// non-Acorn 6502 targets (C64, Apple II, NES) with an appropriate symbol
+// file, and lets users supply their own module-specific zero-page labels.
+type SymbolTable struct {
+	entries map[SymKind]map[uint]string
+}
+
+// NewSymbolTable returns an empty SymbolTable.
+func NewSymbolTable() *SymbolTable {
+	return &SymbolTable{entries: make(map[SymKind]map[uint]string)}
+}
+
+// Load reads "addr name [kind]" entries from r, adding them to the table.
+// addr is hexadecimal, with or without a leading &. kind is one of "oscall",
+// "vector" or "zp", and defaults to "oscall" if omitted. Blank lines and
+// lines starting with # are ignored.
+func (s *SymbolTable) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return fmt.Errorf("bbcdisasm: malformed symbol table line %q", line)
+		}
+
+		addr, err := strconv.ParseUint(strings.TrimPrefix(fields[0], "&"), 16, 32)
+		if err != nil {
+			return fmt.Errorf("bbcdisasm: malformed symbol table address %q: %w", fields[0], err)
+		}
+
+		kind := SymOSCall
+		if len(fields) >= 3 {
+			if kind, err = parseSymKind(fields[2]); err != nil {
+				return err
+			}
+		}
+
+		s.Add(uint(addr), fields[1], kind)
+	}
+	return scanner.Err()
+}
+
+// Add registers a single addr -> name mapping for kind, overwriting any
+// existing entry at that address and kind.
+func (s *SymbolTable) Add(addr uint, name string, kind SymKind) {
+	if s.entries[kind] == nil {
+		s.entries[kind] = make(map[uint]string)
+	}
+	s.entries[kind][addr] = name
+}
+
+// Resolve looks up addr within kind's address space.
+func (s *SymbolTable) Resolve(addr uint, kind SymKind) (string, bool) {
+	name, ok := s.entries[kind][addr]
+	return name, ok
+}
+
+// All returns every addr -> name mapping registered for kind.
+func (s *SymbolTable) All(kind SymKind) map[uint]string {
+	return s.entries[kind]
+}
+
+func parseSymKind(s string) (SymKind, error) {
+	switch strings.ToLower(s) {
+	case "oscall", "":
+		return SymOSCall, nil
+	case "vector":
+		return SymVector, nil
+	case "zp", "zeropage":
+		return SymZeroPage, nil
+	default:
+		return 0, fmt.Errorf("bbcdisasm: unknown symbol kind %q", s)
+	}
+}
+
+// Machine selects a built-in SymbolTable for a particular BBC Micro family
+// member.
+type Machine int
+
+// Machines with a built-in SymbolTable
+const (
+	MachineBBCB     Machine = iota // BBC Model B, OS 1.20
+	MachineElectron                // Acorn Electron
+	MachineMaster                  // BBC Master 128
+)
+
+// DefaultSymbolTable returns the built-in OS call/vector SymbolTable for m.
+// Acorn kept the MOS OS call and vector addresses fixed across the whole
+// range for backwards compatibility: a BBC Micro B disc using OSWRCH at
+// &FFEE runs unmodified on an Electron or Master 128, so addressToOsCallName
+// and osVectorAddresses apply to all three verbatim and m only exists to
+// make that machine choice explicit at the call site, not to select between
+// per-machine data (there is none to select between).
+func DefaultSymbolTable(m Machine) *SymbolTable {
+	t := NewSymbolTable()
+	for addr, name := range addressToOsCallName {
+		t.Add(addr, name, SymOSCall)
+	}
+	for addr, name := range osVectorAddresses {
+		t.Add(addr, name, SymVector)
+	}
+
+	_ = m // every Machine resolves to the same MOS-compatible table; see doc comment above
+
+	return t
+}
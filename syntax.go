@@ -0,0 +1,110 @@
+package bbcdisasm
+
+import "fmt"
+
+// SyntaxDialect renders a decoded disassembly in a particular assembler's
+// source syntax. Disassembler.Dialect selects which one printInstruction,
+// printData, printString, decode and the disassembly header route their
+// output through, so the same decode pass can target beebasm (this repo's
+// long-standing default), ca65, ACME, DASM or xa without duplicating any of
+// the opcode/addressing-mode logic. This mirrors how the Go x/arch disassemblers
+// separate decoding from Intel/GNU/Plan9 syntax rendering.
+//
+// Relative-branch operands are the exception: genBranch writes an unlabelled
+// target as beebasm's P%+n and a BBRn/BBSn zero-page operand with beebasm's
+// "&" prefix, whichever dialect is selected.
+type SyntaxDialect interface {
+	// HexByte formats a single byte as a hex literal, e.g. "&FF" (beebasm)
+	// or "$FF" (ca65).
+	HexByte(b byte) string
+	// HexWord formats a 16-bit address as a hex literal, e.g. "&1234"
+	// (beebasm) or "$1234" (ca65).
+	HexWord(w uint) string
+	// DataDirective introduces a run of raw data bytes, e.g. "EQUB"
+	// (beebasm), ".byte" (ca65), "!byte" (ACME).
+	DataDirective() string
+	// StringDirective introduces a string literal, e.g. "EQUS" (beebasm),
+	// "!text" (ACME). Dialects without a dedicated string directive (ca65,
+	// DASM) reuse DataDirective's value.
+	StringDirective() string
+	// OriginDirective sets the assembly origin, e.g. "ORG" (beebasm),
+	// ".org" (ca65), "!to" (ACME).
+	OriginDirective() string
+	// LabelDef formats name as a label definition at the start of a line,
+	// e.g. ".loop" (beebasm), "loop:" (ca65).
+	LabelDef(name string) string
+	// CommentPrefix introduces an end-of-line comment, e.g. "\" (beebasm),
+	// ";" (ca65, ACME, DASM).
+	CommentPrefix() string
+	// ImmediatePrefix precedes an immediate operand's value, e.g. "#" in
+	// every dialect modelled here.
+	ImmediatePrefix() string
+}
+
+// BeebasmDialect renders disassembly in the beebasm syntax bbcdisasm has
+// always produced: "&" hex, EQUB/EQUS, ".label" definitions and "\"
+// comments. It is the Dialect that NewDisassembler and its variants set by
+// default.
+type BeebasmDialect struct{}
+
+func (BeebasmDialect) HexByte(b byte) string       { return fmt.Sprintf("&%02X", b) }
+func (BeebasmDialect) HexWord(w uint) string       { return fmt.Sprintf("&%04X", w) }
+func (BeebasmDialect) DataDirective() string       { return "EQUB" }
+func (BeebasmDialect) StringDirective() string     { return "EQUS" }
+func (BeebasmDialect) OriginDirective() string     { return "ORG" }
+func (BeebasmDialect) LabelDef(name string) string { return "." + name }
+func (BeebasmDialect) CommentPrefix() string       { return "\\" }
+func (BeebasmDialect) ImmediatePrefix() string     { return "#" }
+
+// CA65Dialect renders disassembly in the syntax of cc65's ca65 assembler:
+// "$" hex, .byte/.org directives, "label:" definitions and ";" comments.
+type CA65Dialect struct{}
+
+func (CA65Dialect) HexByte(b byte) string       { return fmt.Sprintf("$%02X", b) }
+func (CA65Dialect) HexWord(w uint) string       { return fmt.Sprintf("$%04X", w) }
+func (CA65Dialect) DataDirective() string       { return ".byte" }
+func (CA65Dialect) StringDirective() string     { return ".byte" }
+func (CA65Dialect) OriginDirective() string     { return ".org" }
+func (CA65Dialect) LabelDef(name string) string { return name + ":" }
+func (CA65Dialect) CommentPrefix() string       { return ";" }
+func (CA65Dialect) ImmediatePrefix() string     { return "#" }
+
+// ACMEDialect renders disassembly in the syntax of the ACME cross-assembler:
+// "$" hex, !byte/!to directives, "label" definitions and ";" comments.
+type ACMEDialect struct{}
+
+func (ACMEDialect) HexByte(b byte) string       { return fmt.Sprintf("$%02X", b) }
+func (ACMEDialect) HexWord(w uint) string       { return fmt.Sprintf("$%04X", w) }
+func (ACMEDialect) DataDirective() string       { return "!byte" }
+func (ACMEDialect) StringDirective() string     { return "!text" }
+func (ACMEDialect) OriginDirective() string     { return "!to" }
+func (ACMEDialect) LabelDef(name string) string { return name }
+func (ACMEDialect) CommentPrefix() string       { return ";" }
+func (ACMEDialect) ImmediatePrefix() string     { return "#" }
+
+// DASMDialect renders disassembly in the syntax of the DASM assembler:
+// "$" hex, dc.b directives, bare label definitions and ";" comments.
+type DASMDialect struct{}
+
+func (DASMDialect) HexByte(b byte) string       { return fmt.Sprintf("$%02X", b) }
+func (DASMDialect) HexWord(w uint) string       { return fmt.Sprintf("$%04X", w) }
+func (DASMDialect) DataDirective() string       { return "dc.b" }
+func (DASMDialect) StringDirective() string     { return "dc.b" }
+func (DASMDialect) OriginDirective() string     { return "org" }
+func (DASMDialect) LabelDef(name string) string { return name }
+func (DASMDialect) CommentPrefix() string       { return ";" }
+func (DASMDialect) ImmediatePrefix() string     { return "#" }
+
+// XADialect renders disassembly in the syntax of Andre Fachat's xa (xa65)
+// cross-assembler: "$" hex, .byt/.asc/*= directives, bare label definitions
+// and ";" comments.
+type XADialect struct{}
+
+func (XADialect) HexByte(b byte) string       { return fmt.Sprintf("$%02X", b) }
+func (XADialect) HexWord(w uint) string       { return fmt.Sprintf("$%04X", w) }
+func (XADialect) DataDirective() string       { return ".byt" }
+func (XADialect) StringDirective() string     { return ".asc" }
+func (XADialect) OriginDirective() string     { return "*=" }
+func (XADialect) LabelDef(name string) string { return name }
+func (XADialect) CommentPrefix() string       { return ";" }
+func (XADialect) ImmediatePrefix() string     { return "#" }
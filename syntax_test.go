@@ -0,0 +1,116 @@
+package bbcdisasm
+
+import "testing"
+
+// syntaxDialectCase is one dialect's expected rendering of each
+// SyntaxDialect method, checked against its own doc comment in syntax.go.
+type syntaxDialectCase struct {
+	name            string
+	d               SyntaxDialect
+	hexByte         string
+	hexWord         string
+	dataDirective   string
+	stringDirective string
+	originDirective string
+	labelDef        string
+	commentPrefix   string
+	immediatePrefix string
+}
+
+// TestSyntaxDialects spot-checks each SyntaxDialect's distinctive renderings,
+// so a future edit to one dialect's output (e.g. a typo'd directive) fails
+// here instead of only showing up as a cosmetic diff in someone's
+// disassembly.
+func TestSyntaxDialects(t *testing.T) {
+	tests := []syntaxDialectCase{
+		{
+			name:            "beebasm",
+			d:               BeebasmDialect{},
+			hexByte:         "&FF",
+			hexWord:         "&1234",
+			dataDirective:   "EQUB",
+			stringDirective: "EQUS",
+			originDirective: "ORG",
+			labelDef:        ".loop",
+			commentPrefix:   "\\",
+			immediatePrefix: "#",
+		},
+		{
+			name:            "ca65",
+			d:               CA65Dialect{},
+			hexByte:         "$FF",
+			hexWord:         "$1234",
+			dataDirective:   ".byte",
+			stringDirective: ".byte",
+			originDirective: ".org",
+			labelDef:        "loop:",
+			commentPrefix:   ";",
+			immediatePrefix: "#",
+		},
+		{
+			name:            "acme",
+			d:               ACMEDialect{},
+			hexByte:         "$FF",
+			hexWord:         "$1234",
+			dataDirective:   "!byte",
+			stringDirective: "!text",
+			originDirective: "!to",
+			labelDef:        "loop",
+			commentPrefix:   ";",
+			immediatePrefix: "#",
+		},
+		{
+			name:            "dasm",
+			d:               DASMDialect{},
+			hexByte:         "$FF",
+			hexWord:         "$1234",
+			dataDirective:   "dc.b",
+			stringDirective: "dc.b",
+			originDirective: "org",
+			labelDef:        "loop",
+			commentPrefix:   ";",
+			immediatePrefix: "#",
+		},
+		{
+			name:            "xa",
+			d:               XADialect{},
+			hexByte:         "$FF",
+			hexWord:         "$1234",
+			dataDirective:   ".byt",
+			stringDirective: ".asc",
+			originDirective: "*=",
+			labelDef:        "loop",
+			commentPrefix:   ";",
+			immediatePrefix: "#",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.d.HexByte(0xFF); got != tc.hexByte {
+				t.Errorf("HexByte(0xFF) = %q, want %q", got, tc.hexByte)
+			}
+			if got := tc.d.HexWord(0x1234); got != tc.hexWord {
+				t.Errorf("HexWord(0x1234) = %q, want %q", got, tc.hexWord)
+			}
+			if got := tc.d.DataDirective(); got != tc.dataDirective {
+				t.Errorf("DataDirective() = %q, want %q", got, tc.dataDirective)
+			}
+			if got := tc.d.StringDirective(); got != tc.stringDirective {
+				t.Errorf("StringDirective() = %q, want %q", got, tc.stringDirective)
+			}
+			if got := tc.d.OriginDirective(); got != tc.originDirective {
+				t.Errorf("OriginDirective() = %q, want %q", got, tc.originDirective)
+			}
+			if got := tc.d.LabelDef("loop"); got != tc.labelDef {
+				t.Errorf("LabelDef(%q) = %q, want %q", "loop", got, tc.labelDef)
+			}
+			if got := tc.d.CommentPrefix(); got != tc.commentPrefix {
+				t.Errorf("CommentPrefix() = %q, want %q", got, tc.commentPrefix)
+			}
+			if got := tc.d.ImmediatePrefix(); got != tc.immediatePrefix {
+				t.Errorf("ImmediatePrefix() = %q, want %q", got, tc.immediatePrefix)
+			}
+		})
+	}
+}